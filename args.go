@@ -0,0 +1,93 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"sort"
+)
+
+// arguments returns the positional parameters for a command sorted by
+// position
+func (c *configParameters) arguments(cmd string) []*parameter {
+	var ret []*parameter
+	for i := range c.params {
+		if c.params[i].positional && c.params[i].command == cmd {
+			ret = append(ret, &c.params[i])
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].position < ret[j].position
+	})
+	return ret
+}
+
+// checkArguments checks that the positions of the positional parameters
+// start at 0 without gaps and that only the last parameter is a list
+func (c *configParameters) checkArguments() error {
+	commands := []string{""}
+	for _, cmd := range c.commands {
+		commands = append(commands, cmd.name)
+	}
+	for _, cmd := range commands {
+		args := c.arguments(cmd)
+		for i, p := range args {
+			if p.position != i {
+				return tagError(p.name, "arg", "field %s must have position %d", p.name, i)
+			}
+			if p.slice && i != len(args)-1 {
+				return tagError(p.name, "arg", "list field %s must be the last argument", p.name)
+			}
+		}
+	}
+	return nil
+}
+
+// bindArguments sets the positional parameters for a command from the
+// arguments. A list parameter gets the rest of the arguments. The arguments
+// that aren't used are returned.
+func (c *configParameters) bindArguments(cmd string, args []string, source string) ([]string, error) {
+	used := 0
+	for _, p := range c.arguments(cmd) {
+		if p.position >= len(args) {
+			break
+		}
+		var value interface{}
+		if p.slice {
+			values := make([]interface{}, 0, len(args)-p.position)
+			for _, a := range args[p.position:] {
+				v, err := p.parseValue(a)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, v)
+			}
+			value = values
+			used = len(args)
+		} else {
+			v, err := p.parseValue(args[p.position])
+			if err != nil {
+				return nil, err
+			}
+			value = v
+			used = p.position + 1
+		}
+		if err := p.SetValue(value); err != nil {
+			return nil, err
+		}
+		p.source = source
+	}
+	return args[used:], nil
+}
@@ -0,0 +1,144 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+type argsConfig struct {
+	Verbose bool     `param:"desc=Verbose output"`
+	Input   string   `param:"arg=0;desc=Input file;file;required"`
+	Count   int      `param:"arg=1;desc=Count;default=1;max=10"`
+	Files   []string `param:"arg=2;desc=Other files"`
+}
+
+func TestPositionalArguments(t *testing.T) {
+	f, err := ioutil.TempFile("", "args")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	var cfg argsConfig
+	cmd, err := ParseCommand(&cfg, []string{"--verbose", f.Name(), "5", "a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Verbose || cfg.Input != f.Name() || cfg.Count != 5 || len(cfg.Files) != 2 || cfg.Files[1] != "b" {
+		t.Fatalf("Arguments aren't set: %+v", cfg)
+	}
+	if len(cmd.Args) != 0 {
+		t.Fatalf("Expected all arguments to be used: %v", cmd.Args)
+	}
+
+	cfg = argsConfig{}
+	if _, err := ParseCommand(&cfg, []string{"--", f.Name()}); err != nil || cfg.Count != 1 || cfg.Files != nil {
+		t.Fatalf("Expected defaults for optional arguments: %+v %v", cfg, err)
+	}
+	if _, err := ParseCommand(&cfg, []string{}); !errors.Is(err, ErrRequired) {
+		t.Fatalf("Expected required error but got %v", err)
+	}
+	if _, err := ParseCommand(&cfg, []string{f.Name(), "x"}); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("Expected invalid value but got %v", err)
+	}
+	var verr ValidationErrors
+	if _, err := ParseCommand(&cfg, []string{f.Name(), "11"}); !errors.As(err, &verr) || verr[0].Flag != "<count>" {
+		t.Fatalf("Expected range error for <count> but got %v", err)
+	}
+}
+
+func TestLeftoverArguments(t *testing.T) {
+	var cfg struct {
+		Name string `param:"arg=0;desc=Name"`
+	}
+	cmd, err := ParseCommand(&cfg, []string{"--", "foo", "-x", "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "foo" || len(cmd.Args) != 2 || cmd.Args[0] != "-x" {
+		t.Fatalf("Wrong leftover arguments: %+v %v", cfg, cmd.Args)
+	}
+}
+
+func TestArgumentsBeforeTerminator(t *testing.T) {
+	var cfg struct {
+		In string `param:"arg=0;desc=Input"`
+	}
+	cmd, err := ParseCommand(&cfg, []string{"a", "--", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.In != "a" || len(cmd.Args) != 1 || cmd.Args[0] != "b" {
+		t.Fatalf("Wrong arguments: %+v %v", cfg, cmd.Args)
+	}
+
+	var list struct {
+		In    string   `param:"arg=0;desc=Input"`
+		Files []string `param:"arg=1;desc=Files"`
+	}
+	cmd, err = ParseCommand(&list, []string{"a", "x", "--", "b", "--"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.In != "a" || len(list.Files) != 1 || list.Files[0] != "x" ||
+		len(cmd.Args) != 2 || cmd.Args[0] != "b" || cmd.Args[1] != "--" {
+		t.Fatalf("Wrong arguments: %+v %v", list, cmd.Args)
+	}
+}
+
+func TestArgumentUsage(t *testing.T) {
+	var cfg argsConfig
+	buf := &bytes.Buffer{}
+	if err := (&Usage{Program: "svc"}).Print(buf, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Usage: svc [options] <input> [<count>] [<files>...]\n") ||
+		!strings.Contains(out, "Arguments:\n  <input> string\n") ||
+		strings.Contains(out, "--input") {
+		t.Fatalf("Wrong help text:\n%s", out)
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	var gap struct {
+		First  string `param:"arg=0;desc=First"`
+		Second string `param:"arg=2;desc=Second"`
+	}
+	if _, err := ParseCommand(&gap, []string{}); err == nil {
+		t.Fatal("Expected error with gap in positions")
+	}
+	var list struct {
+		First  []string `param:"arg=0;desc=First"`
+		Second string   `param:"arg=1;desc=Second"`
+	}
+	if _, err := ParseCommand(&list, []string{}); err == nil {
+		t.Fatal("Expected error when list isn't last")
+	}
+	var invalid struct {
+		First string `param:"arg=x;desc=First"`
+	}
+	if _, err := ParseCommand(&invalid, []string{}); err == nil {
+		t.Fatal("Expected error with invalid position")
+	}
+}
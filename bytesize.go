@@ -0,0 +1,143 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes. Sizes can be specified with units, either
+// decimal (kB, MB, GB, TB, PB) or binary (KiB, MiB, GiB, TiB, PiB). Single
+// letter units (k, m, g, t, p) are binary units. Units are case insensitive
+// and fractions are allowed, ie "512KiB", "1.5GB" and "64m" are all valid
+// sizes. The min and max keywords can be used with units as well:
+//
+//  type config struct {
+//      CacheSize params.ByteSize `param:"desc=Cache size;default=64MiB;max=4GiB"`
+//  }
+//
+type ByteSize uint64
+
+// Byte size units
+const (
+	Byte     ByteSize = 1
+	KiloByte ByteSize = 1000
+	MegaByte ByteSize = 1000 * KiloByte
+	GigaByte ByteSize = 1000 * MegaByte
+	TeraByte ByteSize = 1000 * GigaByte
+	PetaByte ByteSize = 1000 * TeraByte
+	KibiByte ByteSize = 1 << 10
+	MebiByte ByteSize = 1 << 20
+	GibiByte ByteSize = 1 << 30
+	TebiByte ByteSize = 1 << 40
+	PebiByte ByteSize = 1 << 50
+)
+
+var byteUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"kb":  KiloByte,
+	"mb":  MegaByte,
+	"gb":  GigaByte,
+	"tb":  TeraByte,
+	"pb":  PetaByte,
+	"k":   KibiByte,
+	"m":   MebiByte,
+	"g":   GibiByte,
+	"t":   TebiByte,
+	"p":   PebiByte,
+	"kib": KibiByte,
+	"mib": MebiByte,
+	"gib": GibiByte,
+	"tib": TebiByte,
+	"pib": PebiByte,
+}
+
+// formatUnits is the list of units used when formatting sizes, largest first.
+var formatUnits = []struct {
+	size ByteSize
+	name string
+}{
+	{PebiByte, "PiB"}, {PetaByte, "PB"},
+	{TebiByte, "TiB"}, {TeraByte, "TB"},
+	{GibiByte, "GiB"}, {GigaByte, "GB"},
+	{MebiByte, "MiB"}, {MegaByte, "MB"},
+	{KibiByte, "KiB"}, {KiloByte, "kB"},
+}
+
+// ParseByteSize parses a size with an optional unit.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	number, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	mult, ok := byteUnits[unit]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	if !strings.Contains(number, ".") {
+		v, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid size: %s", s)
+		}
+		if v > math.MaxUint64/uint64(mult) {
+			return 0, fmt.Errorf("size %s is too large", s)
+		}
+		return ByteSize(v) * mult, nil
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	v *= float64(mult)
+	if v >= math.MaxUint64 {
+		return 0, fmt.Errorf("size %s is too large", s)
+	}
+	return ByteSize(v), nil
+}
+
+// Set sets the size from a string. This implements the Value interface.
+func (b *ByteSize) Set(s string) error {
+	v, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// String returns the size with the largest unit that represents the
+// size exactly, ie 4GiB or 1500MB.
+func (b ByteSize) String() string {
+	for _, u := range formatUnits {
+		if b >= u.size && b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
+
+// Type returns the type name used in help texts
+func (b *ByteSize) Type() string {
+	return "size"
+}
@@ -0,0 +1,125 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected ByteSize
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"512KiB", 512 * KibiByte},
+		{"1.5GB", 1500 * MegaByte},
+		{"64m", 64 * MebiByte},
+		{"64 MB", 64 * MegaByte},
+		{"4gib", 4 * GibiByte},
+		{"1kb", KiloByte},
+		{"1.5k", 1536},
+		{"16EB", 0},
+	}
+	for _, tc := range tests {
+		v, err := ParseByteSize(tc.s)
+		if tc.expected == 0 && tc.s != "0" {
+			if err == nil {
+				t.Fatalf("Expected error for %s", tc.s)
+			}
+			continue
+		}
+		if err != nil || v != tc.expected {
+			t.Fatalf("Parsing %s returned %d (%v), expected %d", tc.s, v, err, tc.expected)
+		}
+	}
+	for _, s := range []string{"", "KiB", "1.2.3MB", "12 apples", "99999999999PiB", "-1k"} {
+		if _, err := ParseByteSize(s); err == nil {
+			t.Fatalf("Expected error for %q", s)
+		}
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	tests := map[ByteSize]string{
+		0:                   "0B",
+		100:                 "100B",
+		4 * GibiByte:        "4GiB",
+		1500 * MegaByte:     "1500MB",
+		512 * KibiByte:      "512KiB",
+		KiloByte:            "1kB",
+		GibiByte + 1:        "1073741825B",
+		3 * PebiByte:        "3PiB",
+		2 * TeraByte:        "2TB",
+		MegaByte + KiloByte: "1001kB",
+	}
+	for v, expected := range tests {
+		if s := v.String(); s != expected {
+			t.Fatalf("Expected %s but got %s", expected, s)
+		}
+	}
+	if s := fmt.Sprint(64 * MebiByte); s != "64MiB" {
+		t.Fatalf("Expected 64MiB but got %s", s)
+	}
+}
+
+func TestByteSizeParameter(t *testing.T) {
+	var cfg struct {
+		CacheSize  ByteSize   `param:"desc=Cache size;default=64MiB;min=1MiB;max=4GiB"`
+		BufferSize *ByteSize  `param:"desc=Buffer size"`
+		Limits     []ByteSize `param:"desc=Limits"`
+	}
+	if err := NewFlag(&cfg, []string{"--limits", "1k,2k"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CacheSize != 64*MebiByte || cfg.BufferSize != nil || len(cfg.Limits) != 2 || cfg.Limits[1] != 2048 {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+	if err := NewFlag(&cfg, []string{"--cache-size", "5GiB"}); err == nil {
+		t.Fatal("Expected error when size is above max")
+	}
+	if err := NewFlag(&cfg, []string{"--cache-size", "512KiB"}); err == nil {
+		t.Fatal("Expected error when size is below min")
+	}
+
+	os.Setenv("BUFFER_SIZE", "1.5GB")
+	defer os.Unsetenv("BUFFER_SIZE")
+	if err := NewEnv(&cfg); err != nil || cfg.BufferSize == nil || *cfg.BufferSize != 1500*MegaByte {
+		t.Fatalf("Size isn't set from environment: %v %+v", err, cfg)
+	}
+
+	if err := NewFile(&cfg, strings.NewReader(`{"cacheSize": "2GiB", "limits": [1024, "1m"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CacheSize != 2*GibiByte || cfg.Limits[0] != KibiByte || cfg.Limits[1] != MebiByte {
+		t.Fatalf("Values aren't set from file: %+v", cfg)
+	}
+	if s := formatValue(cfg.CacheSize); s != "2GiB" {
+		t.Fatalf("Default should be human readable: %s", s)
+	}
+
+	var invalid struct {
+		Size ByteSize `param:"desc=foo;max=4 apples"`
+	}
+	if err := NewEnv(&invalid); err == nil {
+		t.Fatal("Expected error with invalid max")
+	}
+}
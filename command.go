@@ -0,0 +1,170 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// Command is the command selected on the command line
+type Command struct {
+	Name string   // The command name, ie "user add". This is empty if no command is selected
+	Args []string // The remaining arguments after the command and its flags
+}
+
+// command is a command declared in the configuration
+type command struct {
+	name        string // The command name, ie "user add"
+	prefix      string // The prefix for the parameters in the command, ie "User.Add."
+	description string
+}
+
+// newCommand returns the command for a field with the cmd keyword in the tag.
+// It returns nil if the field isn't a command. The command name is the
+// hyphenated field name prefixed with the parent command.
+func newCommand(prefix string, parent *command, field reflect.StructField) (*command, error) {
+	tagValue, ok := field.Tag.Lookup(tagName)
+	if !ok {
+		return nil, nil
+	}
+	ret := command{prefix: prefix + field.Name + "."}
+	isCommand := false
+	for _, v := range strings.Split(tagValue, ";") {
+		tv := strings.SplitN(v, "=", 2)
+		switch strings.ToLower(strings.TrimSpace(tv[0])) {
+		case "cmd":
+			isCommand = true
+		case "desc":
+			if len(tv) == 2 {
+				ret.description = tv[1]
+			}
+		}
+	}
+	if !isCommand {
+		return nil, nil
+	}
+	name := prefix + field.Name
+	for _, v := range strings.Split(tagValue, ";") {
+		switch kw := strings.ToLower(strings.TrimSpace(strings.SplitN(v, "=", 2)[0])); kw {
+		case "cmd", "desc", "":
+		default:
+			return nil, tagError(name, kw, "command %s can only have a description", name)
+		}
+	}
+	if structType(field.Type) == nil || toInternalType(reflect.Zero(structType(field.Type)).Interface()) != invalidType {
+		return nil, tagError(name, "cmd", "command %s must be a struct", name)
+	}
+	ret.name = hyphenate(field.Name)
+	if parent != nil {
+		ret.name = parent.name + " " + ret.name
+	}
+	return &ret, nil
+}
+
+// structType returns the struct type for structs and pointers to structs.
+// Other types return nil.
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// selectedCommand returns the name of the selected command
+func (c *configParameters) selectedCommand() string {
+	if c.command == nil {
+		return ""
+	}
+	return c.command.Name
+}
+
+// isActive returns true if the parameter is a global parameter or belongs to
+// the command or one of its parents
+func (c *configParameters) isActive(p *parameter, cmd string) bool {
+	return p.command == "" || p.command == cmd || strings.HasPrefix(cmd, p.command+" ")
+}
+
+// getCommand returns the command with the name or nil if it doesn't exist
+func (c *configParameters) getCommand(name string) *command {
+	for i := range c.commands {
+		if c.commands[i].name == name {
+			return &c.commands[i]
+		}
+	}
+	return nil
+}
+
+// commandByPrefix returns the command with the parameter prefix or nil if it
+// doesn't exist
+func (c *configParameters) commandByPrefix(prefix string) *command {
+	for i := range c.commands {
+		if c.commands[i].prefix == prefix {
+			return &c.commands[i]
+		}
+	}
+	return nil
+}
+
+// subCommands returns the commands directly below the command. The top level
+// commands are returned for the empty name.
+func (c *configParameters) subCommands(name string) []*command {
+	var ret []*command
+	for i := range c.commands {
+		n := c.commands[i].name
+		if name != "" {
+			if !strings.HasPrefix(n, name+" ") {
+				continue
+			}
+			n = n[len(name)+1:]
+		}
+		if !strings.Contains(n, " ") {
+			ret = append(ret, &c.commands[i])
+		}
+	}
+	return ret
+}
+
+// ParseCommand parses the command line for a configuration with commands.
+// Commands are struct fields with the cmd keyword in the tag:
+//
+//  type config struct {
+//      Verbose bool       `param:"desc=Verbose output"`
+//      Serve   serveCmd   `param:"cmd;desc=Run the server"`
+//      User    struct {
+//          Add userAddCmd `param:"cmd;desc=Add a user"`
+//      } `param:"cmd;desc=Manage users"`
+//  }
+//
+// The command names are the hyphenated field names, ie "serve" and
+// "user add". The flags for the parameters in a command are relative to the
+// command (--port rather than --serve-port) and the flags for the global
+// parameters and the parent commands can be used after the command name:
+//
+//  my-command --verbose serve --port 8080
+//  my-command user add --verbose --name foo
+//
+// The parameters are only validated for the selected command. The returned
+// Command has the name of the selected command and the remaining arguments.
+// Errors are returned like ParseFlag. The command is returned with ErrHelp so
+// you can print the help text for the command with Usage.
+func ParseCommand(config interface{}, args []string) (*Command, error) {
+	return NewLoader(&flagSource{args: args, errorHandling: flag.ContinueOnError}).LoadCommand(config)
+}
@@ -0,0 +1,131 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type serveCmd struct {
+	Port int `param:"desc=Port;default=8080"`
+}
+
+type userAddCmd struct {
+	UserName string `param:"desc=User name;required"`
+}
+
+type commandConfig struct {
+	Verbose bool     `param:"desc=Verbose output"`
+	Serve   serveCmd `param:"cmd;desc=Run the server"`
+	User    struct {
+		Add userAddCmd `param:"cmd;desc=Add a user"`
+	} `param:"cmd;desc=Manage users"`
+}
+
+func TestParseCommand(t *testing.T) {
+	var cfg commandConfig
+	cmd, err := ParseCommand(&cfg, []string{"--verbose", "serve", "--port", "9090", "extra"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Name != "serve" || len(cmd.Args) != 1 || cmd.Args[0] != "extra" {
+		t.Fatalf("Wrong command: %+v", cmd)
+	}
+	if !cfg.Verbose || cfg.Serve.Port != 9090 {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+
+	// Global flags are inherited by the commands
+	cfg = commandConfig{}
+	cmd, err = ParseCommand(&cfg, []string{"user", "add", "--verbose", "--user-name", "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Name != "user add" || !cfg.Verbose || cfg.User.Add.UserName != "foo" {
+		t.Fatalf("Wrong command or values: %+v %+v", cmd, cfg)
+	}
+
+	// Required parameters are only checked for the selected command
+	cmd, err = ParseCommand(&cfg, []string{})
+	if err != nil || cmd.Name != "" {
+		t.Fatalf("Expected no command and no error: %+v %v", cmd, err)
+	}
+	if _, err := ParseCommand(&cfg, []string{"user", "add"}); !errors.Is(err, ErrRequired) {
+		t.Fatalf("Expected required error but got %v", err)
+	}
+
+	// Command flags can't be used before the command
+	if _, err := ParseCommand(&cfg, []string{"--port", "1", "serve"}); err == nil {
+		t.Fatal("Expected error with command flag before the command")
+	}
+	cmd, err = ParseCommand(&cfg, []string{"serve", "--help"})
+	if err != ErrHelp || cmd.Name != "serve" {
+		t.Fatalf("Expected ErrHelp for serve but got %+v %v", cmd, err)
+	}
+	cmd, err = ParseCommand(&cfg, []string{"--", "serve"})
+	if err != nil || cmd.Name != "" || len(cmd.Args) != 1 {
+		t.Fatalf("Expected no command after --: %+v %v", cmd, err)
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	var cfg commandConfig
+	buf := &bytes.Buffer{}
+	if err := (&Usage{Program: "svc"}).Print(buf, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Usage: svc [options] <command> [options]") ||
+		!strings.Contains(out, "  serve  Run the server\n") ||
+		!strings.Contains(out, "  user   Manage users\n") ||
+		strings.Contains(out, "--port") {
+		t.Fatalf("Wrong top level help text:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := (&Usage{Program: "svc", Command: "user add"}).Print(buf, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	out = buf.String()
+	if !strings.Contains(out, "Usage: svc user add [options]\n\nAdd a user\n") ||
+		!strings.Contains(out, "--verbose") ||
+		!strings.Contains(out, "user add options:\n  --user-name string\n") ||
+		strings.Contains(out, "--port") {
+		t.Fatalf("Wrong command help text:\n%s", out)
+	}
+}
+
+func TestInvalidCommands(t *testing.T) {
+	var notStruct struct {
+		Serve string `param:"cmd;desc=Not a struct"`
+	}
+	if _, err := ParseCommand(&notStruct, []string{}); err == nil {
+		t.Fatal("Expected error when command isn't a struct")
+	}
+	var duplicate struct {
+		Port  int `param:"desc=Port"`
+		Serve struct {
+			Port int `param:"desc=Port"`
+		} `param:"cmd"`
+	}
+	var tagErr *TagError
+	if _, err := ParseCommand(&duplicate, []string{"serve"}); !errors.As(err, &tagErr) {
+		t.Fatalf("Expected TagError with duplicate flags but got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The reserved command line parameter and environment variable for the
+// configuration file used by Load
+const (
+	ConfigFlag = "config"
+	ConfigEnv  = "CONFIG_FILE"
+)
+
+// Load populates the configuration from a configuration file, environment
+// variables and the command line, in that order of precedence. The
+// configuration file is set with the --config command line parameter or the
+// CONFIG_FILE environment variable. The file is optional. Files ending in
+// .yaml or .yml are read as YAML, .toml as TOML, .ini as INI, .properties
+// as Java properties, .env as dotenv files, .jsonc and .json5 as JSON with
+// comments (see JSONC, the rest of JSON5 isn't supported) and other files as
+// JSON. Defaults are only
+// applied once so values from the file are kept unless they are set in the
+// environment or on the command line:
+//
+//  var config parameters
+//  if err := params.Load(&config, os.Args[1:]); err != nil {
+//      ...
+//  }
+//
+// Errors are returned like ParseFlag, ie ErrHelp is returned when -h or
+// --help is used. Print the help text with Usage and ConfigFlag set to list
+// the --config parameter. The configuration can't have a parameter named "config" or
+// a parameter with the environment variable CONFIG_FILE, ie ConfigFile.
+func Load(config interface{}, args []string) error {
+	return NewLoader(
+		&configFileSource{args: args},
+		EnvSource(),
+		&flagSource{args: args, errorHandling: flag.ContinueOnError, configFlag: true}).Load(config)
+}
+
+// configFileSource reads the file given with --config or CONFIG_FILE
+type configFileSource struct {
+	args []string
+}
+
+func (c *configFileSource) Name() string {
+	return SourceFile
+}
+
+func (c *configFileSource) Load(set *ParameterSet) error {
+	path := ""
+	_, values, err := parseFlags(set.params, c.args, flag.ContinueOnError, true)
+	var tagErr *TagError
+	if errors.As(err, &tagErr) {
+		return err
+	}
+	// Other errors are ignored here. They are reported when the flags are loaded.
+	if values != nil {
+		path = values.configFile
+	}
+	if path == "" {
+		path = os.Getenv(ConfigEnv)
+	}
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fileSourceFor(path, f).Load(set)
+}
+
+// fileSourceFor returns the file source for the file's extension
+func fileSourceFor(path string, reader io.Reader) Source {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAMLSource(reader)
+	case ".toml":
+		return TOMLSource(reader)
+	case ".ini":
+		return INISource(reader)
+	case ".properties":
+		return PropertiesSource(reader)
+	case ".env":
+		return DotEnvSource(reader, false)
+	case ".jsonc", ".json5":
+		return FileSource(reader, JSONC())
+	}
+	return FileSource(reader)
+}
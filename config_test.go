@@ -0,0 +1,107 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+type loadConfig struct {
+	LoadName    string `param:"desc=Name;default=default"`
+	LoadPort    int    `param:"desc=Port;default=80"`
+	LoadTimeout string `param:"desc=Timeout;default=1s"`
+	LoadLevel   string `param:"desc=Level;default=info"`
+}
+
+func writeTempFile(t *testing.T, contents string) string {
+	f, err := ioutil.TempFile("", "params")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestLoad(t *testing.T) {
+	name := writeTempFile(t, `{"loadName": "file", "loadPort": 8080, "loadTimeout": "2s"}`)
+	defer os.Remove(name)
+
+	os.Setenv("LOAD_TIMEOUT", "3s")
+	defer os.Unsetenv("LOAD_TIMEOUT")
+
+	var cfg loadConfig
+	if err := Load(&cfg, []string{"--config", name, "--load-port", "9090"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LoadName != "file" || cfg.LoadPort != 9090 || cfg.LoadTimeout != "3s" || cfg.LoadLevel != "info" {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+
+	os.Setenv(ConfigEnv, name)
+	defer os.Unsetenv(ConfigEnv)
+	cfg = loadConfig{}
+	if err := Load(&cfg, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LoadName != "file" || cfg.LoadPort != 8080 {
+		t.Fatalf("Values aren't set from CONFIG_FILE: %+v", cfg)
+	}
+
+	if err := Load(&cfg, []string{"--config", name + ".missing"}); err == nil {
+		t.Fatal("Expected error when file doesn't exist")
+	}
+	if err := Load(&cfg, []string{"--help"}); err != ErrHelp {
+		t.Fatalf("Expected ErrHelp but got %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := (&Usage{ConfigFlag: true}).Print(buf, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Options:\n") ||
+		!strings.Contains(buf.String(), "  --config string\n        Configuration file\n        Environment: CONFIG_FILE\n  -h, --help\n") {
+		t.Fatalf("Help text doesn't list --config:\n%s", buf.String())
+	}
+	if err := Load(&cfg, []string{"--unknown"}); err == nil {
+		t.Fatal("Expected error with unknown flag")
+	}
+	os.Unsetenv(ConfigEnv)
+	cfg = loadConfig{}
+	if err := Load(&cfg, []string{}); err != nil || cfg.LoadName != "default" {
+		t.Fatalf("Expected defaults without a config file: %v %+v", err, cfg)
+	}
+
+	var reserved struct {
+		Config string `param:"desc=Config"`
+	}
+	var tagErr *TagError
+	if err := Load(&reserved, []string{}); !errors.As(err, &tagErr) {
+		t.Fatalf("Expected TagError for reserved name but got %v", err)
+	}
+	var reservedEnv struct {
+		ConfigFile string `param:"desc=Config file"`
+	}
+	if err := Load(&reservedEnv, []string{}); !errors.As(err, &tagErr) {
+		t.Fatalf("Expected TagError for reserved environment variable but got %v", err)
+	}
+}
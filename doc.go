@@ -4,7 +4,24 @@
 // structs within structs for parameters.
 //
 // A limited number of data types are supported: strings (string), integers
-// (int, uint), booleans (bool), duration (time.Duration) and floats (float64)
+// (int, int8, int16, int32, int64 and the unsigned equivalents), booleans
+// (bool), duration (time.Duration), floats (float32, float64), timestamps
+// (time.Time), URLs (url.URL), IP addresses (net.IP), networks (net.IPNet),
+// regular expressions (regexp.Regexp) and sizes (ByteSize) plus slices of
+// these types ([]string, []int, []time.Duration...). Slices are set with
+// repeated command line flags, comma-separated environment variables or JSON
+// arrays. Maps with string keys (map[string]string, map[string]int...) are set
+// with repeated key=value flags, comma-separated key=value pairs in
+// environment variables or JSON objects.
+//
+// Custom types can be used if the pointer type implements the Value interface
+// or encoding.TextUnmarshaler. Custom types are set from their string
+// representation regardless of the source.
+//
+// Pointer fields (*int, *string, *time.Duration...) are left as nil unless the
+// parameter is set or has a default value. This makes it possible to tell an
+// explicit zero value from a parameter that isn't set. Pointers to nested
+// structs are allocated when one of the parameters in the struct is set.
 //
 // The tags are set with the keyword "param". The fields must be publicly accessible:
 //
@@ -13,15 +30,39 @@
 //   }
 //
 // Keywords are separated by semicolons. There is no escaping so defaults
-// can't contain equal or semicolons. The following keywords are supported:
+// can't contain semicolons. The following keywords are supported:
 //
 //  desc      - a description
 //  default   - the default value for the parameter
-//  min       - minimum value for parameter. Flag must be int, uint, float or Duration
-//  max       - maximum value for parameter. Flag must be int, uint, float or Duration
+//  min       - minimum value for parameter. Flag must be int, uint, float, ByteSize, Duration or Time
+//  max       - maximum value for parameter. Flag must be int, uint, float, ByteSize, Duration or Time
 //  file      - if present the flag points to a file and that file must exist. Flag must be a string.
 //  required  - if present the flag must be specfified in a valid config
 //  options   - a list of options. Type must be string. Options are case insensitive.
+//  minlen    - minimum number of elements. Flag must be a slice or map.
+//  maxlen    - maximum number of elements. Flag must be a slice or map.
+//  layout    - the time layout to use. Flag must be a time.Time. The default is RFC3339.
+//
+//  cmd       - the struct is a command. Only desc can be used with cmd. See ParseCommand.
+//  arg       - the position of a positional argument, starting at 0. A slice gets the rest of the arguments.
+//  short     - a single letter flag, ie short=v for -v
+//  alias     - other names for the flag, ie alias=old-name,other-name
+//
+// The min and max values use the same format as the parameter itself, ie
+// min=100ms for durations and max=4GiB for sizes.
+//
+// The min, max, file and options keywords apply to each element for slices
+// and each value for maps.
+//
+// Errors
+//
+// Invalid tags or field types are reported as *TagError. These are
+// programming errors. Values that can't be parsed are reported as *ParseError
+// and values that fail validation are reported as ValidationErrors. Use
+// errors.Is with ErrRequired, ErrOutOfRange, ErrInvalidOption,
+// ErrInvalidLength and ErrInvalidValue to check for specific failures.
+// Unknown flags match ErrUnknownParameter and flags without a value match
+// ErrMissingValue.
 //
 package params
 
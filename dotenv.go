@@ -0,0 +1,234 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// NewDotEnv populates a configuration with values from a .env file. The
+// variables in the file use the same names as the environment variables
+// (see NewEnv):
+//
+//  # The HTTP endpoint
+//  export HTTP_ENDPOINT=localhost:1234
+//  LOG_TYPE="fancy"
+//  DATA_DIR=${HOME}/data
+//
+// If envOverride is true the real environment variables override the values
+// in the file.
+func NewDotEnv(config interface{}, reader io.Reader, envOverride bool) error {
+	return NewLoader(DotEnvSource(reader, envOverride)).Load(config)
+}
+
+// dotEnvSource reads environment variables from a .env file
+type dotEnvSource struct {
+	reader      io.Reader
+	envOverride bool
+}
+
+// DotEnvSource returns a source that reads the parameters from a .env file.
+// See NewDotEnv for the format. If envOverride is true the real environment
+// variables override the values in the file and the source is reported as
+// the environment.
+func DotEnvSource(reader io.Reader, envOverride bool) Source {
+	return &dotEnvSource{reader: reader, envOverride: envOverride}
+}
+
+func (d *dotEnvSource) Name() string {
+	return SourceFile
+}
+
+func (d *dotEnvSource) Load(set *ParameterSet) error {
+	if d.reader == nil {
+		return ErrNilReader
+	}
+	buf, err := ioutil.ReadAll(d.reader)
+	if err != nil {
+		return err
+	}
+	vars, err := parseDotEnv(string(buf))
+	if err != nil {
+		return err
+	}
+	for i := range set.params.params {
+		p := &set.params.params[i]
+		source := set.source
+		v, ok := vars[p.envName()]
+		if d.envOverride {
+			if env, found := os.LookupEnv(p.envName()); found {
+				v, ok, source = env, true, SourceEnvironment
+			}
+		}
+		if !ok {
+			continue
+		}
+		if err := p.SetValueAsString(v); err != nil {
+			return err
+		}
+		p.source = source
+	}
+	return nil
+}
+
+// parseDotEnv reads the variables in a .env file. Double quoted values can
+// use escapes, single quoted values are used as is and both can span several
+// lines. ${VAR} and $VAR are expanded in double quoted and unquoted values
+// from the variables set earlier in the file or from the environment.
+func parseDotEnv(contents string) (map[string]string, error) {
+	vars := make(map[string]string)
+	lookup := func(name string) string {
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	}
+	lines := strings.Split(strings.Replace(contents, "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := i + 1
+		text := strings.TrimSpace(lines[i])
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(text, "export ") || strings.HasPrefix(text, "export\t") {
+			text = strings.TrimSpace(text[len("export"):])
+		}
+		eq := strings.IndexByte(text, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=value but got %q", line, text)
+		}
+		key := strings.TrimSpace(text[:eq])
+		if !validEnvName(key) {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", line, key)
+		}
+		value := strings.TrimLeft(text[eq+1:], " \t")
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			// Unquoted values end at a comment
+			if n := strings.Index(value, " #"); n >= 0 {
+				value = value[:n]
+			}
+			vars[key] = expandDotEnv(strings.TrimSpace(value), false, lookup)
+			continue
+		}
+		quote := value[0]
+		rest := value[1:]
+		end := closingQuote(rest, quote)
+		for end < 0 {
+			i++
+			if i >= len(lines) {
+				return nil, fmt.Errorf("line %d: unterminated quoted value for %s", line, key)
+			}
+			rest += "\n" + lines[i]
+			end = closingQuote(rest, quote)
+		}
+		if trailing := strings.TrimSpace(rest[end+1:]); trailing != "" && trailing[0] != '#' {
+			return nil, fmt.Errorf("line %d: unexpected %q after quoted value for %s", line, trailing, key)
+		}
+		if quote == '\'' {
+			vars[key] = rest[:end]
+			continue
+		}
+		vars[key] = expandDotEnv(rest[:end], true, lookup)
+	}
+	return vars, nil
+}
+
+// validEnvName returns true if the name is a valid variable name
+func validEnvName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for i := range name {
+		if !isEnvChar(name[i]) && name[i] != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// isEnvChar returns true for the characters in variable names used in
+// expansions
+func isEnvChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// closingQuote returns the index of the closing quote or -1 if there is
+// none. Double quotes can be escaped with a backslash.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// expandDotEnv expands variables and, if escapes is set, escape sequences
+func expandDotEnv(s string, escapes bool, lookup func(string) string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if escapes && c == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '"', '\\', '$':
+				sb.WriteByte(s[i])
+			default:
+				sb.WriteByte('\\')
+				sb.WriteByte(s[i])
+			}
+			continue
+		}
+		if c != '$' || i+1 == len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+		if s[i+1] == '{' {
+			if end := strings.IndexByte(s[i+2:], '}'); end >= 0 {
+				sb.WriteString(lookup(s[i+2 : i+2+end]))
+				i += end + 2
+				continue
+			}
+			sb.WriteByte(c)
+			continue
+		}
+		end := i + 1
+		for end < len(s) && isEnvChar(s[end]) {
+			end++
+		}
+		if end == i+1 {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteString(lookup(s[i+1 : end]))
+		i = end - 1
+	}
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	os.Setenv("DOTENV_HOME", "/home/test")
+	defer os.Unsetenv("DOTENV_HOME")
+
+	file := `
+# comment
+PLAIN=value # trailing comment
+export EXPORTED=exported
+DOUBLE="a \"quoted\"\tvalue"
+SINGLE='no $EXPANSION \n here'
+MULTI="first
+second"
+DIR=${DOTENV_HOME}/data
+NESTED="$PLAIN-${DIR}"
+ESCAPED="\$PLAIN"
+EMPTY=
+`
+	vars, err := parseDotEnv(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"PLAIN":    "value",
+		"EXPORTED": "exported",
+		"DOUBLE":   "a \"quoted\"\tvalue",
+		"SINGLE":   "no $EXPANSION \\n here",
+		"MULTI":    "first\nsecond",
+		"DIR":      "/home/test/data",
+		"NESTED":   "value-/home/test/data",
+		"ESCAPED":  "$PLAIN",
+		"EMPTY":    "",
+	}
+	for k, v := range expected {
+		if vars[k] != v {
+			t.Fatalf("Expected %s=%q but got %q", k, v, vars[k])
+		}
+	}
+
+	for _, invalid := range []string{"NOVALUE", "1KEY=a", "KEY=\"unterminated", "KEY='a' b"} {
+		if _, err := parseDotEnv(invalid); err == nil {
+			t.Fatalf("Expected error with %q", invalid)
+		}
+	}
+}
+
+func TestDotEnv(t *testing.T) {
+	var cfg struct {
+		LogType string   `param:"desc=Log type;default=plain"`
+		Peers   []string `param:"desc=Peers"`
+		HTTP    struct {
+			Endpoint string `param:"desc=Endpoint"`
+		}
+	}
+	if err := NewDotEnv(&cfg, nil, false); err == nil {
+		t.Fatal("Expected error with nil reader")
+	}
+
+	file := "LOG_TYPE=fancy\nPEERS=a,b\nHTTP_ENDPOINT=localhost:1234\n"
+	os.Setenv("LOG_TYPE", "env")
+	defer os.Unsetenv("LOG_TYPE")
+
+	if err := NewDotEnv(&cfg, strings.NewReader(file), false); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LogType != "fancy" || len(cfg.Peers) != 2 || cfg.HTTP.Endpoint != "localhost:1234" {
+		t.Fatalf("Values aren't set from file: %+v", cfg)
+	}
+	if err := NewDotEnv(&cfg, strings.NewReader(file), true); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LogType != "env" || cfg.HTTP.Endpoint != "localhost:1234" {
+		t.Fatalf("Environment doesn't override file: %+v", cfg)
+	}
+}
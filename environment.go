@@ -21,19 +21,33 @@ import (
 
 // NewEnv populates a configuration with values from environment variables.
 func NewEnv(config interface{}) error {
-	params, err := newConfigParameters(config)
-	if err != nil {
-		return err
-	}
-	for i := range params.params {
-		v, ok := os.LookupEnv(params.params[i].envName())
+	return NewLoader(EnvSource()).Load(config)
+}
+
+// envSource reads values from environment variables
+type envSource struct {
+}
+
+// EnvSource returns a source that reads the parameters from environment
+// variables. The names are the upper case parameter names with underscores,
+// ie HTTP_ENDPOINT.
+func EnvSource() Source {
+	return &envSource{}
+}
+
+func (e *envSource) Name() string {
+	return SourceEnvironment
+}
+
+func (e *envSource) Load(set *ParameterSet) error {
+	for _, p := range set.Parameters() {
+		v, ok := os.LookupEnv(p.Env)
 		if !ok {
 			continue
 		}
-		if err := params.params[i].SetValueAsString(v); err != nil {
+		if err := set.Set(p.Name, v); err != nil {
 			return err
 		}
 	}
-	params.AssignValues(config)
-	return params.Validate()
+	return nil
 }
@@ -96,3 +96,50 @@ func TestEnvironment(t *testing.T) {
 		t.Fatalf("Config not set properly: %+v", cfg)
 	}
 }
+
+func TestEnvironmentSlice(t *testing.T) {
+	var cfg struct {
+		EnvPeers []string `param:"desc=Peers;minlen=2"`
+		EnvPorts []uint   `param:"desc=Ports;maxlen=2"`
+	}
+	os.Setenv("ENV_PEERS", "a, b,c")
+	os.Setenv("ENV_PORTS", "1,2")
+	defer os.Unsetenv("ENV_PEERS")
+	defer os.Unsetenv("ENV_PORTS")
+	if err := NewEnv(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.EnvPeers) != 3 || cfg.EnvPeers[1] != "b" || len(cfg.EnvPorts) != 2 {
+		t.Fatalf("Config not set properly: %+v", cfg)
+	}
+	os.Setenv("ENV_PORTS", "1,2,3")
+	if err := NewEnv(&cfg); err == nil {
+		t.Fatal("Expected error when list is too long")
+	}
+	os.Setenv("ENV_PEERS", "a")
+	os.Setenv("ENV_PORTS", "1")
+	if err := NewEnv(&cfg); err == nil {
+		t.Fatal("Expected error when list is too short")
+	}
+}
+
+func TestEnvironmentMap(t *testing.T) {
+	var cfg struct {
+		EnvLabels map[string]string `param:"desc=Labels;default=a=0"`
+		EnvLimits map[string]uint   `param:"desc=Limits"`
+	}
+	os.Setenv("ENV_LABELS", "a=1,b=2")
+	os.Setenv("ENV_LIMITS", "x=10")
+	defer os.Unsetenv("ENV_LABELS")
+	defer os.Unsetenv("ENV_LIMITS")
+	if err := NewEnv(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.EnvLabels) != 2 || cfg.EnvLabels["a"] != "1" || cfg.EnvLimits["x"] != 10 {
+		t.Fatalf("Config not set properly: %+v", cfg)
+	}
+	os.Setenv("ENV_LIMITS", "x=-1")
+	if err := NewEnv(&cfg); err == nil {
+		t.Fatal("Expected error with invalid map value")
+	}
+}
@@ -0,0 +1,240 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Validation rules reported in ValidationError
+const (
+	RuleRequired = "required"
+	RuleMin      = "min"
+	RuleMax      = "max"
+	RuleMinLen   = "minlen"
+	RuleMaxLen   = "maxlen"
+	RuleOptions  = "options"
+	RuleFile     = "file"
+)
+
+// Sources reported in ValidationError
+const (
+	SourceDefault     = "default"
+	SourceFlag        = "flag"
+	SourceEnvironment = "environment"
+	SourceFile        = "file"
+)
+
+// ValidationError is a single parameter that failed validation.
+type ValidationError struct {
+	Name   string // The parameter name, ie HTTP.Endpoint
+	Flag   string // The command line flag, ie --http-endpoint
+	Env    string // The environment variable, ie HTTP_ENDPOINT
+	Value  string // The offending value. This is empty if the parameter isn't set
+	Source string // Where the value came from. This is empty if the parameter isn't set
+	Rule   string // The rule that failed, ie RuleMin
+	Err    error  // The underlying error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is the list of parameters that failed validation. All of
+// the parameters are validated so the list contains every failure. Use
+// errors.As to get the list from the returned error.
+type ValidationErrors []*ValidationError
+
+// Error returns a report with one line per failure. A single failure is
+// reported as is.
+func (v ValidationErrors) Error() string {
+	if len(v) == 1 {
+		return v[0].Error()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d parameters are invalid:", len(v))
+	for _, e := range v {
+		fmt.Fprintf(&sb, "\n  %s (%s): %s", e.Flag, e.Env, e.Error())
+		if e.Source != "" {
+			fmt.Fprintf(&sb, " [%s]", e.Source)
+		}
+	}
+	return sb.String()
+}
+
+// Sentinel errors. Use errors.Is to check for these.
+var (
+	// ErrRequired is returned when a required parameter isn't set
+	ErrRequired = errors.New("parameter is required")
+	// ErrOutOfRange is returned when a value is outside the min and max range
+	ErrOutOfRange = errors.New("value is out of range")
+	// ErrInvalidOption is returned when the value isn't one of the options
+	ErrInvalidOption = errors.New("invalid option")
+	// ErrInvalidLength is returned when a list or map has too few or too many values
+	ErrInvalidLength = errors.New("invalid number of values")
+	// ErrInvalidValue is returned when a value can't be parsed. All ParseErrors
+	// match this error.
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrHelp is returned by ParseFlag and ParseEnvFlag when -h or --help is
+	// used on the command line
+	ErrHelp = errors.New("help requested")
+	// ErrMissingValue is returned when a flag on the command line needs a
+	// value and there are no more arguments
+	ErrMissingValue = errors.New("flag needs an argument")
+	// ErrNilConfig is returned when the configuration is nil
+	ErrNilConfig = errors.New("config must be non-nil")
+	// ErrNilReader is returned by the file sources when the reader is nil
+	ErrNilReader = errors.New("reader must be non-nil")
+	// ErrNotPointer is returned when the configuration isn't a pointer
+	ErrNotPointer = errors.New("needs pointer to configuration")
+	// ErrNotStruct is returned when the configuration isn't a struct
+	ErrNotStruct = errors.New("needs struct type for configuration")
+)
+
+// Is maps the validation rule to the sentinel errors so errors.Is works
+func (e *ValidationError) Is(target error) bool {
+	switch e.Rule {
+	case RuleRequired:
+		return target == ErrRequired
+	case RuleMin, RuleMax:
+		return target == ErrOutOfRange
+	case RuleOptions:
+		return target == ErrInvalidOption
+	case RuleMinLen, RuleMaxLen:
+		return target == ErrInvalidLength
+	}
+	return false
+}
+
+// Is returns true if one of the errors in the list matches the target
+func (v ValidationErrors) Is(target error) bool {
+	for _, e := range v {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in the list that matches the target
+func (v ValidationErrors) As(target interface{}) bool {
+	for _, e := range v {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// TagError is returned when the struct tags or the field types in the
+// configuration are invalid. This is a programming error, not an error in the
+// supplied configuration values.
+type TagError struct {
+	Field   string // The field name, ie HTTP.Endpoint
+	Keyword string // The tag keyword that is invalid. Empty if the field itself is invalid
+	msg     string
+}
+
+func (e *TagError) Error() string {
+	return e.msg
+}
+
+func tagError(field, keyword, format string, args ...interface{}) *TagError {
+	return &TagError{Field: field, Keyword: keyword, msg: fmt.Sprintf(format, args...)}
+}
+
+// ParseError is returned when a value from the command line, the environment
+// or a configuration file can't be parsed or has the wrong type. ParseErrors
+// match ErrInvalidValue with errors.Is.
+type ParseError struct {
+	Name  string // The parameter name, ie HTTP.Endpoint
+	Value string // The value that couldn't be parsed
+	Err   error  // The underlying error. This might be nil
+	msg   string
+}
+
+func (e *ParseError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true for ErrInvalidValue
+func (e *ParseError) Is(target error) bool {
+	return target == ErrInvalidValue
+}
+
+// UnknownKeyError is a key in a configuration file that doesn't match any of
+// the parameters. UnknownKeyErrors match ErrUnknownParameter with errors.Is.
+type UnknownKeyError struct {
+	Key        string // The path to the key in the file, ie http.endpiont
+	Suggestion string // The closest parameter name, ie HTTP.Endpoint. Empty if nothing is close
+}
+
+func (e *UnknownKeyError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("unknown key %s (did you mean %s?)", e.Key, e.Suggestion)
+	}
+	return fmt.Sprintf("unknown key %s", e.Key)
+}
+
+// Is returns true for ErrUnknownParameter
+func (e *UnknownKeyError) Is(target error) bool {
+	return target == ErrUnknownParameter
+}
+
+// UnknownKeyErrors is the list of unknown keys in a configuration file. It is
+// returned by the file sources in strict mode.
+type UnknownKeyErrors []*UnknownKeyError
+
+// Error returns a report with one line per key. A single key is reported as
+// is.
+func (u UnknownKeyErrors) Error() string {
+	if len(u) == 1 {
+		return u[0].Error()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d keys are unknown:", len(u))
+	for _, e := range u {
+		fmt.Fprintf(&sb, "\n  %s", e.Error())
+	}
+	return sb.String()
+}
+
+// Is returns true for ErrUnknownParameter
+func (u UnknownKeyErrors) Is(target error) bool {
+	return target == ErrUnknownParameter
+}
+
+// As finds the first error in the list that matches the target
+func (u UnknownKeyErrors) As(target interface{}) bool {
+	for _, e := range u {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestValidationErrors(t *testing.T) {
+	var cfg struct {
+		Name    string   `param:"desc=Name;required"`
+		Workers int      `param:"desc=Workers;min=1;max=10"`
+		LogType string   `param:"desc=Log type;options=plain,json;default=xml"`
+		Peers   []string `param:"desc=Peers;minlen=2"`
+	}
+	os.Setenv("WORKERS", "20")
+	defer os.Unsetenv("WORKERS")
+	err := NewEnvFlag(&cfg, []string{"--peers", "a"})
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	var verr ValidationErrors
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected ValidationErrors but got %T", err)
+	}
+	if len(verr) != 4 {
+		t.Fatalf("Expected 4 errors but got %d: %v", len(verr), err)
+	}
+	expected := []struct {
+		name, flag, env, value, source, rule string
+	}{
+		{"Name", "--name", "NAME", "", "", RuleRequired},
+		{"Workers", "--workers", "WORKERS", "20", SourceEnvironment, RuleMax},
+		{"LogType", "--log-type", "LOG_TYPE", "xml", SourceDefault, RuleOptions},
+		{"Peers", "--peers", "PEERS", "a", SourceFlag, RuleMinLen},
+	}
+	for i, e := range expected {
+		v := verr[i]
+		if v.Name != e.name || v.Flag != e.flag || v.Env != e.env || v.Value != e.value || v.Source != e.source || v.Rule != e.rule {
+			t.Fatalf("Unexpected error %d: %+v", i, v)
+		}
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 5 || lines[0] != "4 parameters are invalid:" {
+		t.Fatalf("Unexpected report:\n%s", err.Error())
+	}
+	if lines[2] != "  --workers (WORKERS): value 20 for Workers is above the maximum 10 [environment]" {
+		t.Fatalf("Unexpected line in report: %s", lines[2])
+	}
+
+	var single ValidationErrors
+	err = NewFlag(&cfg, []string{"--name", "foo", "--log-type", "json", "--peers", "a,b"})
+	if !errors.As(err, &single) || len(single) != 1 || single[0].Rule != RuleMin ||
+		err.Error() != "value 0 for Workers is below the minimum 1" {
+		t.Fatalf("Expected a single error but got %v", err)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	var tagCfg struct {
+		Val string `param:"desc=foo;min=1"`
+	}
+	err := NewEnv(&tagCfg)
+	var tagErr *TagError
+	if !errors.As(err, &tagErr) || tagErr.Field != "Val" || tagErr.Keyword != "min" {
+		t.Fatalf("Expected TagError but got %v", err)
+	}
+	if errors.Is(err, ErrInvalidValue) {
+		t.Fatal("Tag errors should not be invalid values")
+	}
+	var defaultCfg struct {
+		Val int `param:"default=foo"`
+	}
+	if err := NewEnv(&defaultCfg); !errors.As(err, &tagErr) || tagErr.Keyword != "default" {
+		t.Fatalf("Expected TagError for default but got %v", err)
+	}
+	var noValue struct {
+		Val string `param:"desc"`
+	}
+	if err := NewEnv(&noValue); err != nil {
+		t.Fatalf("Keyword without value should be allowed: %v", err)
+	}
+
+	if err := NewEnv(nil); !errors.Is(err, ErrNilConfig) {
+		t.Fatalf("Expected ErrNilConfig but got %v", err)
+	}
+	if err := NewEnv(tagCfg); !errors.Is(err, ErrNotPointer) {
+		t.Fatalf("Expected ErrNotPointer but got %v", err)
+	}
+	for _, source := range []Source{FileSource(nil), YAMLSource(nil), TOMLSource(nil), INISource(nil), PropertiesSource(nil), DotEnvSource(nil, false)} {
+		if err := NewLoader(source).Load(&noValue); !errors.Is(err, ErrNilReader) {
+			t.Fatalf("Expected ErrNilReader but got %v", err)
+		}
+	}
+	params, err := newConfigParameters(&noValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params.params[0].value = "foo"
+	var wrongType struct {
+		Val int
+	}
+	if err := params.AssignValues(&wrongType); !errors.As(err, &tagErr) || tagErr.Field != "Val" {
+		t.Fatalf("Expected TagError for type mismatch but got %v", err)
+	}
+
+	var cfg struct {
+		Small   int8     `param:"desc=Small"`
+		Name    string   `param:"desc=Name;required"`
+		Workers int      `param:"desc=Workers;min=1;max=10;default=1"`
+		LogType string   `param:"desc=Log type;options=plain,json;default=plain"`
+		Peers   []string `param:"desc=Peers;maxlen=1"`
+	}
+	os.Setenv("SMALL", "1000")
+	err = NewEnv(&cfg)
+	os.Unsetenv("SMALL")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Name != "Small" || parseErr.Value != "1000" {
+		t.Fatalf("Expected ParseError but got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidValue) || !errors.Is(err, strconv.ErrRange) {
+		t.Fatalf("Expected ParseError to match ErrInvalidValue and ErrRange: %v", err)
+	}
+	if err := NewFile(&cfg, strings.NewReader(`{"peers": "a"}`)); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("Expected ErrInvalidValue but got %v", err)
+	}
+
+	err = NewFlag(&cfg, []string{})
+	if !errors.Is(err, ErrRequired) || errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("Expected ErrRequired but got %v", err)
+	}
+	err = NewFlag(&cfg, []string{"--workers=11", "--log-type=xml", "--peers=a,b"})
+	for _, target := range []error{ErrRequired, ErrOutOfRange, ErrInvalidOption, ErrInvalidLength} {
+		if !errors.Is(err, target) {
+			t.Fatalf("Expected %v in %v", target, err)
+		}
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Rule != RuleRequired {
+		t.Fatalf("Expected the first ValidationError but got %v", verr)
+	}
+}
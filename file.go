@@ -16,88 +16,289 @@ package params
 //limitations under the License.
 //
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
-	"time"
 )
 
 // Flatten nested JSON structs into a single level, ie to internal representation
-// of config.
-func flattenMap(prefix string, in, out map[string]interface{}) {
+// of config. Objects for parameters are kept as is. The keys are the paths
+// in the file, the parameter names are case insensitive.
+func flattenMap(prefix string, in, out map[string]interface{}, params *configParameters) {
 	for k, v := range in {
 		submap, ok := v.(map[string]interface{})
 		if ok {
-			flattenMap(prefix+strings.ToLower(k)+".", submap, out)
-			continue
+			if params.getParameter(prefix+k) == nil {
+				flattenMap(prefix+k+".", submap, out, params)
+				continue
+			}
 		}
-		out[prefix+strings.ToLower(k)] = v
+		out[prefix+k] = v
 	}
 }
 
-// NewFile populates a config struct with values from a config file
-func NewFile(config interface{}, reader io.Reader) error {
-	params, err := newConfigParameters(config)
-	if err != nil {
+// FileOption is an option for the configuration file sources
+type FileOption func(*fileOptions)
+
+type fileOptions struct {
+	strict    bool
+	onUnknown func(err *UnknownKeyError)
+	jsonc     bool
+}
+
+// Strict makes the configuration file sources return UnknownKeyErrors when a
+// key in the file doesn't match any of the parameters. Unknown keys are
+// ignored by default.
+func Strict() FileOption {
+	return func(o *fileOptions) {
+		o.strict = true
+	}
+}
+
+// WarnUnknown calls the function for every key in the configuration file that
+// doesn't match any of the parameters. Use this to log misspelled keys
+// without failing.
+func WarnUnknown(fn func(err *UnknownKeyError)) FileOption {
+	return func(o *fileOptions) {
+		o.onUnknown = fn
+	}
+}
+
+func newFileOptions(opts []FileOption) fileOptions {
+	var ret fileOptions
+	for _, opt := range opts {
+		opt(&ret)
+	}
+	return ret
+}
+
+// checkUnknown reports the unknown keys from a file
+func (o *fileOptions) checkUnknown(params *configParameters, keys []string) error {
+	if len(keys) == 0 || (!o.strict && o.onUnknown == nil) {
+		return nil
+	}
+	sort.Strings(keys)
+	var errs UnknownKeyErrors
+	for _, k := range keys {
+		e := &UnknownKeyError{Key: k, Suggestion: params.closestName(k)}
+		if o.onUnknown != nil {
+			o.onUnknown(e)
+		}
+		errs = append(errs, e)
+	}
+	if o.strict {
+		return errs
+	}
+	return nil
+}
+
+// jsonValue converts a single value from JSON into the parameter's type.
+// Numbers are decoded as json.Number so they are parsed with the precision
+// and size of the field. Strings, numbers and booleans are parsed from their
+// string representation so "12" works for integers and "true" for booleans.
+// String fields must be strings.
+func jsonValue(para *parameter, v interface{}) (interface{}, error) {
+	if _, ok := v.(string); !ok && para.paramtype == stringType {
+		return nil, para.typeError(v, "field %s must be a string", para.name)
+	}
+	switch tmp := v.(type) {
+	case string:
+		return para.parseValue(tmp)
+	case json.Number:
+		s := tmp.String()
+		if (para.paramtype == intType || para.paramtype == uintType) && strings.ContainsAny(s, ".eE") {
+			// Integers can be written as 1.0 or 1e3 in JSON but not 1.5
+			f, err := tmp.Float64()
+			if err != nil || f != math.Trunc(f) {
+				return nil, para.typeError(s, "value %s for field %s isn't an integer", s, para.name)
+			}
+			s = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return para.parseValue(s)
+	case bool:
+		return para.parseValue(strconv.FormatBool(tmp))
+	case nil:
+		return nil, para.typeError("null", "field %s can't contain null", para.name)
+	}
+	return nil, para.typeError(v, "field %s must be a single value", para.name)
+}
+
+// jsonSyntaxError adds the line and column to JSON syntax errors. The
+// inserted list is the offsets of the characters inserted when JSONC is
+// converted to JSON so the position is in the original file.
+func jsonSyntaxError(data []byte, inserted []int64, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
 		return err
 	}
-	if reader == nil {
-		return errors.New("reader must be non-nil")
+	// The offset is after the offending character
+	return jsonPositionError(data, inserted, offset-1, err)
+}
+
+// jsonPositionError adds the line and column for an offset in the converted
+// input to the error
+func jsonPositionError(data []byte, inserted []int64, offset int64, err error) error {
+	adjusted := offset
+	for _, n := range inserted {
+		if n < offset {
+			adjusted--
+		}
+	}
+	line, col := position(data, adjusted)
+	return fmt.Errorf("line %d, column %d: %w", line, col, err)
+}
+
+// position returns the line and column for an offset
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	line, col := 1, 1
+	for _, b := range data[:offset] {
+		col++
+		if b == '\n' {
+			line++
+			col = 1
+		}
+	}
+	return line, col
+}
+
+// NewFile populates a config struct with values from a config file
+func NewFile(config interface{}, reader io.Reader, opts ...FileOption) error {
+	return NewLoader(FileSource(reader, opts...)).Load(config)
+}
+
+// fileSource reads values from a JSON file
+type fileSource struct {
+	reader  io.Reader
+	options fileOptions
+}
+
+// FileSource returns a source that reads the parameters from a JSON file.
+// See NewFile for the format.
+func FileSource(reader io.Reader, opts ...FileOption) Source {
+	return &fileSource{reader: reader, options: newFileOptions(opts)}
+}
+
+func (f *fileSource) Name() string {
+	return SourceFile
+}
+
+func (f *fileSource) Load(set *ParameterSet) error {
+	params := set.params
+	if f.reader == nil {
+		return ErrNilReader
 	}
 
-	bytes, err := ioutil.ReadAll(reader)
+	data, err := ioutil.ReadAll(f.reader)
 	if err != nil {
 		return err
 	}
+	input := data
+	var inserted []int64
+	if f.options.jsonc {
+		if input, inserted, err = convertJSONC(data); err != nil {
+			return err
+		}
+	}
 	jsonMap := make(map[string]interface{})
-	err = json.Unmarshal(bytes, &jsonMap)
+	reader := bytes.NewReader(input)
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	if err := decoder.Decode(&jsonMap); err != nil {
+		return jsonSyntaxError(data, inserted, err)
+	}
+	// Only whitespace is allowed after the object
+	buffered, _ := ioutil.ReadAll(decoder.Buffered())
+	offset := len(input) - reader.Len() - len(buffered)
+	if rest := bytes.TrimLeft(input[offset:], " \t\r\n"); len(rest) > 0 {
+		offset = len(input) - len(rest)
+		return jsonPositionError(data, inserted, int64(offset), fmt.Errorf("invalid character %q after top-level value", rest[0]))
+	}
+	// Flatten config into keys
+	configMap := make(map[string]interface{})
+	flattenMap("", jsonMap, configMap, params)
+	unknown, err := setValues(set, configMap, jsonValue)
 	if err != nil {
 		return err
 	}
-	// Flatten config into keys, all lowercase
-	configMap := make(map[string]interface{})
-	flattenMap("", jsonMap, configMap)
+	return f.options.checkUnknown(params, unknown)
+}
+
+// setValues sets the values from a flattened configuration file. The convert
+// function converts single values into the parameter's type. Null values are
+// ignored. Errors include the path to the value and the keys that don't match
+// a parameter are returned.
+func setValues(set *ParameterSet, configMap map[string]interface{}, convert func(*parameter, interface{}) (interface{}, error)) ([]string, error) {
+	var err error
+	var unknown []string
 	for k, v := range configMap {
-		// Fix the types if int or uint is used
-		para := params.getParameter(k)
+		para := set.params.getParameter(k)
 		if para == nil {
+			unknown = append(unknown, k)
 			continue
 		}
-		if para.paramtype == intType {
-			tmp := v.(float64)
-			if err := para.SetValue(int(tmp)); err != nil {
-				return err
-			}
+		if v == nil {
 			continue
 		}
-		if para.paramtype == uintType {
-			tmp := v.(float64)
-			if err := para.SetValue(uint(tmp)); err != nil {
-				return err
+		var val interface{}
+		switch {
+		case para.isMap:
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, pathError(k, para.typeError(v, "field %s must be an object", para.name))
 			}
-			continue
-		}
-		if para.paramtype == durationType {
-			tmp, ok := v.(string)
+			values := make(map[string]interface{})
+			for mk, mv := range obj {
+				if values[mk], err = convert(para, mv); err != nil {
+					return nil, pathError(k+"."+mk, err)
+				}
+			}
+			val = values
+		case para.slice:
+			list, ok := v.([]interface{})
 			if !ok {
-				return fmt.Errorf("can't parse duration field %s", para.name)
+				return nil, pathError(k, para.typeError(v, "field %s must be a list", para.name))
 			}
-			d, err := time.ParseDuration(tmp)
-			if err != nil {
-				return fmt.Errorf("field %s isn't properly formatted", para.name)
+			values := make([]interface{}, len(list))
+			for i := range list {
+				if values[i], err = convert(para, list[i]); err != nil {
+					return nil, pathError(fmt.Sprintf("%s[%d]", k, i), err)
+				}
+			}
+			val = values
+		default:
+			if val, err = convert(para, v); err != nil {
+				return nil, pathError(k, err)
 			}
-			para.SetValue(d)
-			continue
 		}
-		if err := para.SetValue(v); err != nil {
-			return err
+		if err := para.SetValue(val); err != nil {
+			return nil, pathError(k, err)
 		}
+		para.source = set.source
 	}
-	if err := params.AssignValues(config); err != nil {
-		return err
-	}
-	return params.Validate()
+	return unknown, nil
+}
+
+// pathError adds the path to the value in the file to an error
+func pathError(path string, err error) error {
+	return fmt.Errorf("%s: %w", path, err)
 }
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParameterFile(t *testing.T) {
@@ -143,3 +144,167 @@ func TestInvalidConfig(t *testing.T) {
 		t.Fatal("Expected error")
 	}
 }
+
+func TestFileSlice(t *testing.T) {
+	var cfg struct {
+		Hosts   []string        `param:"desc=Hosts;options=a,b,c"`
+		Ports   []int           `param:"desc=Ports"`
+		Timeout []time.Duration `param:"desc=Timeouts"`
+	}
+	file := `{
+		"hosts": ["a", "c"],
+		"ports": [1, 2, 3],
+		"timeout": ["1s", "2ms"]
+	}`
+	if err := NewFile(&cfg, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Hosts) != 2 || cfg.Hosts[1] != "c" {
+		t.Fatalf("Hosts not set: %+v", cfg)
+	}
+	if len(cfg.Ports) != 3 || cfg.Ports[2] != 3 {
+		t.Fatalf("Ports not set: %+v", cfg)
+	}
+	if len(cfg.Timeout) != 2 || cfg.Timeout[1] != 2*time.Millisecond {
+		t.Fatalf("Timeouts not set: %+v", cfg)
+	}
+	if err := NewFile(&cfg, strings.NewReader(`{"hosts": ["a", "d"]}`)); err == nil {
+		t.Fatal("Expected error with invalid option in list")
+	}
+	if err := NewFile(&cfg, strings.NewReader(`{"hosts": "a"}`)); err == nil {
+		t.Fatal("Expected error when list isn't a list")
+	}
+}
+
+func TestFileMap(t *testing.T) {
+	var cfg struct {
+		Tenant  map[string]int           `param:"desc=Tenants"`
+		Timeout map[string]time.Duration `param:"desc=Timeouts"`
+		Nested  struct {
+			Labels map[string]string `param:"desc=Nested labels"`
+		}
+	}
+	file := `{
+		"tenant": {"a": 1, "b": 2},
+		"timeout": {"read": "1s"},
+		"nested": {
+			"labels": {"key": "value"}
+		}
+	}`
+	if err := NewFile(&cfg, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Tenant) != 2 || cfg.Tenant["b"] != 2 || cfg.Timeout["read"] != time.Second {
+		t.Fatalf("Maps not set: %+v", cfg)
+	}
+	if cfg.Nested.Labels["key"] != "value" {
+		t.Fatalf("Nested map not set: %+v", cfg)
+	}
+	if err := NewFile(&cfg, strings.NewReader(`{"tenant": [1, 2]}`)); err == nil {
+		t.Fatal("Expected error when map isn't an object")
+	}
+}
+
+func TestStrictFile(t *testing.T) {
+	config := parameters{}
+	file := `{
+		"http": {
+			"endpiont": ":1234"
+		},
+		"myVal": 12,
+		"unrelated": true
+	}`
+	if err := NewFile(&config, strings.NewReader(file)); err != nil {
+		t.Fatal("Unknown keys should be ignored by default: ", err)
+	}
+
+	err := NewFile(&config, strings.NewReader(file), Strict())
+	if !errors.Is(err, ErrUnknownParameter) {
+		t.Fatalf("Expected ErrUnknownParameter but got %v", err)
+	}
+	var unknown UnknownKeyErrors
+	if !errors.As(err, &unknown) || len(unknown) != 2 {
+		t.Fatalf("Expected two unknown keys but got %v", err)
+	}
+	if unknown[0].Key != "http.endpiont" || unknown[0].Suggestion != "HTTP.Endpoint" {
+		t.Fatalf("Unexpected key and suggestion: %+v", unknown[0])
+	}
+	if unknown[1].Key != "unrelated" || unknown[1].Suggestion != "" {
+		t.Fatalf("Unexpected key and suggestion: %+v", unknown[1])
+	}
+
+	var warnings []string
+	warn := WarnUnknown(func(err *UnknownKeyError) {
+		warnings = append(warnings, err.Error())
+	})
+	if err := NewFile(&config, strings.NewReader(file), warn); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 || warnings[0] != "unknown key http.endpiont (did you mean HTTP.Endpoint?)" {
+		t.Fatalf("Unexpected warnings: %v", warnings)
+	}
+	if config.MyVal != 12 {
+		t.Fatalf("Known values aren't set: %+v", config)
+	}
+
+	if err := NewYAMLFile(&config, strings.NewReader("http:\n  endpiont: x\n"), Strict()); !errors.Is(err, ErrUnknownParameter) {
+		t.Fatalf("Expected unknown key in YAML but got %v", err)
+	}
+	if err := NewINIFile(&config, strings.NewReader("[http]\nendpiont = x\n"), Strict()); !errors.Is(err, ErrUnknownParameter) {
+		t.Fatalf("Expected unknown key in INI but got %v", err)
+	}
+}
+
+func TestFileTypes(t *testing.T) {
+	var cfg struct {
+		Count   int     `param:"desc=Count;default=5"`
+		Big     int64   `param:"desc=Big number"`
+		Ratio   float64 `param:"desc=Ratio"`
+		Enabled bool    `param:"desc=Enabled"`
+		Depth   uint8   `param:"desc=Depth"`
+		Ports   []int   `param:"desc=Ports"`
+	}
+	file := `{
+		"count": null,
+		"big": 9007199254740993,
+		"ratio": "0.25",
+		"enabled": "true",
+		"depth": 1e2
+	}`
+	if err := NewFile(&cfg, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Count != 5 || cfg.Big != 9007199254740993 || cfg.Ratio != 0.25 || !cfg.Enabled || cfg.Depth != 100 {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+
+	if err := NewFile(&cfg, strings.NewReader(`{"count": "12"}`)); err != nil || cfg.Count != 12 {
+		t.Fatalf("Expected string-encoded integer to work: %v", err)
+	}
+	if err := NewFile(&cfg, strings.NewReader("{\"count\": 1}\n\n")); err != nil || cfg.Count != 1 {
+		t.Fatalf("Expected trailing whitespace to work: %v", err)
+	}
+	for _, file := range []string{`{"count": 1} garbage`, `{"count": 1} {"count": 2}`, `{"count": 1} }`} {
+		if err := NewFile(&cfg, strings.NewReader(file)); err == nil || !strings.HasPrefix(err.Error(), "line 1, column 14: ") {
+			t.Fatalf("Expected error with data after the object in %s but got %v", file, err)
+		}
+	}
+	err := NewFile(&cfg, strings.NewReader(`{"count": 1.5}`))
+	if !errors.Is(err, ErrInvalidValue) || !strings.HasPrefix(err.Error(), "count: ") {
+		t.Fatalf("Expected error for fractional integer but got %v", err)
+	}
+	err = NewFile(&cfg, strings.NewReader(`{"ports": [1, "x"]}`))
+	if err == nil || !strings.HasPrefix(err.Error(), "ports[1]: ") {
+		t.Fatalf("Expected error with path but got %v", err)
+	}
+	if err := NewFile(&cfg, strings.NewReader(`{"depth": 300}`)); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("Expected overflow but got %v", err)
+	}
+	if err := NewFile(&cfg, strings.NewReader(`{"count": {"a": 1}}`)); err == nil {
+		t.Fatal("Expected error when value is an object")
+	}
+	err = NewFile(&cfg, strings.NewReader("{\n\t\"count\": 1,\n\t\"big\" 2\n}"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3, column") {
+		t.Fatalf("Expected error with position but got %v", err)
+	}
+}
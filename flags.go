@@ -16,96 +16,16 @@ package params
 //limitations under the License.
 //
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
-	"time"
+	"strings"
 )
 
-type flagDef struct {
-	flagName  string
-	name      string
-	value     interface{}
-	paramtype internalType
-}
-
-// flagValue returns the value as a scalar value
-func (d *flagDef) flagValue() interface{} {
-	switch d.paramtype {
-	case stringType:
-		return *d.value.(*string)
-
-	case boolType:
-		return *d.value.(*bool)
-	case intType:
-		return *d.value.(*int)
-	case uintType:
-		return *d.value.(*uint)
-	case floatType:
-		return *d.value.(*float64)
-	case durationType:
-		return *d.value.(*time.Duration)
-	default:
-		panic(fmt.Sprintf("can't %v", d.paramtype))
-	}
-}
-
-func defaultAsInt(defaultVal string) int {
-	if defaultVal == "" {
-		return 0
-	}
-	dv, _ := strconv.ParseInt(defaultVal, 10, 64)
-	return int(dv)
-}
-
-func defaultAsBool(defaultVal string) bool {
-	d, _ := strconv.ParseBool(defaultVal)
-	return d
-}
-func defaultAsFloat64(defaultVal string) float64 {
-	d, _ := strconv.ParseFloat(defaultVal, 32)
-	return d
-}
-func defaultAsDuration(defaultVal string) time.Duration {
-	d, _ := time.ParseDuration(defaultVal)
-	return d
-}
-func makeFlag(fs *flag.FlagSet, p parameter) (*flagDef, error) {
-	ret := flagDef{flagName: p.hyphenName(), name: p.name, paramtype: p.paramtype}
-	switch p.paramtype {
-	case stringType:
-		var s string
-		ret.value = &s
-		fs.StringVar(&s, ret.flagName, p.defaultValue, p.description)
-	case boolType:
-		var b bool
-		ret.value = &b
-		fs.BoolVar(&b, ret.flagName, defaultAsBool(p.defaultValue), p.description)
-	case intType:
-		var v int
-		ret.value = &v
-		fs.IntVar(&v, ret.flagName, defaultAsInt(p.defaultValue), p.description)
-	case uintType:
-		var v uint
-		ret.value = &v
-		fs.UintVar(&v, ret.flagName, uint(defaultAsInt(p.defaultValue)), p.description)
-	case floatType:
-		var v float64
-		ret.value = &v
-		fs.Float64Var(&v, ret.flagName, defaultAsFloat64(p.defaultValue), p.description)
-	case durationType:
-		var v time.Duration
-		ret.value = &v
-		fs.DurationVar(&v, ret.flagName, defaultAsDuration(p.defaultValue), p.description)
-	default:
-		return nil, fmt.Errorf("can't make flag for %s:%v", p.name, p.paramtype)
-	}
-	return &ret, nil
-}
-
-// NewFlag parses the command line parameters. This uses the flag package
-// internally. Flag names are derived from the names in the configuration structures
+// NewFlag parses the command line parameters. Flag names are derived from the
+// names in the configuration structures
 // If you have structs within structs the names are prefixed with the name of the
 // structure containing the fields:
 //
@@ -119,70 +39,363 @@ func makeFlag(fs *flag.FlagSet, p parameter) (*flagDef, error) {
 //      TLS      bool    // This parameter will be named http-tls
 //  }
 //
+// Slice parameters can be repeated on the command line (--peer a --peer b) or
+// set with a comma-separated list (--peer a,b). Map parameters are set with
+// key=value pairs in the same way (--label a=1 --label b=2).
+//
+// Values can be set with --flag=value or --flag value. Boolean flags are set
+// with --flag or --flag=false. Use the short keyword in the tag to add a
+// single letter flag (short=v for -v) and the alias keyword to add other
+// names (alias=old-name). Short flags can be combined, ie -vqf file is the
+// same as -v -q -f file. The process exits if the command line is invalid.
 func NewFlag(config interface{}, args []string) error {
 	return newFlagWithErrorHandling(config, args, flag.ExitOnError, false)
 }
 
 // NewEnvFlag returns a struct populated with settings from environment
 // variables and command line arguments. The command line arguments overrides
-// the environment variables.
+// the environment variables. This is the same as a Loader with EnvSource and
+// FlagSource.
 func NewEnvFlag(config interface{}, args []string) error {
 	return newFlagWithErrorHandling(config, args, flag.ExitOnError, true)
 }
 
-// newFlagWithErrorHandling is just for testing; flag.ContinueOnError
-// keeps executing but returns an error
-func newFlagWithErrorHandling(config interface{}, args []string, opt flag.ErrorHandling, envOverride bool) error {
-	params, err := newConfigParameters(config)
-	if err != nil {
-		return err
+// ParseFlag works like NewFlag but it returns an error instead of printing
+// the error and exiting the process when the command line is invalid. Nothing
+// is printed. ErrHelp is returned when -h or --help is used; use PrintUsage
+// to print the help text.
+func ParseFlag(config interface{}, args []string) error {
+	return newFlagWithErrorHandling(config, args, flag.ContinueOnError, false)
+}
+
+// ParseEnvFlag works like NewEnvFlag but it returns an error instead of
+// exiting the process. See ParseFlag.
+func ParseEnvFlag(config interface{}, args []string) error {
+	return newFlagWithErrorHandling(config, args, flag.ContinueOnError, true)
+}
+
+// PrintUsage prints the help text for the configuration to the writer. Use
+// Usage if you want to add a description or a footer.
+func PrintUsage(w io.Writer, config interface{}) error {
+	return (&Usage{}).Print(w, config)
+}
+
+// flagTable is the flags for a command
+type flagTable struct {
+	long       map[string]*parameter
+	short      map[string]*parameter
+	configFlag bool
+}
+
+// newFlagTable creates the flags for the parameters in a command. The global
+// parameters and the parameters in the parent commands are included. The
+// configuration file flag is added if configFlag is set.
+func newFlagTable(params *configParameters, cmd string, configFlag bool) (*flagTable, error) {
+	ret := &flagTable{long: make(map[string]*parameter), short: make(map[string]*parameter), configFlag: configFlag}
+	for i := range params.params {
+		p := &params.params[i]
+		if configFlag && p.envName() == ConfigEnv {
+			return nil, tagError(p.name, "", "the environment variable %s is reserved for the configuration file", ConfigEnv)
+		}
+		if !params.isActive(p, cmd) || p.positional {
+			continue
+		}
+		for _, name := range append([]string{p.flagName()}, p.aliases...) {
+			if ret.long[name] != nil {
+				return nil, tagError(p.name, "", "the flag --%s is used by more than one parameter", name)
+			}
+			ret.long[name] = p
+		}
+		if p.short == "" {
+			continue
+		}
+		if ret.short[p.short] != nil {
+			return nil, tagError(p.name, "short", "the flag -%s is used by more than one parameter", p.short)
+		}
+		ret.short[p.short] = p
 	}
+	if configFlag && ret.long[ConfigFlag] != nil {
+		return nil, tagError("Config", "", "the parameter --%s is reserved for the configuration file", ConfigFlag)
+	}
+	return ret, nil
+}
 
-	fs := flag.NewFlagSet("parameters", opt)
+// flagValues is the values set on the command line. Repeated flags are
+// appended for lists and maps.
+type flagValues struct {
+	params     []*parameter
+	values     map[*parameter]interface{}
+	configFile string
+	extra      []string // The arguments after a -- that follows the positional arguments
+}
 
-	flagVars := make([]*flagDef, len(params.params))
-	for i, p := range params.params {
-		flagVars[i], err = makeFlag(fs, p)
+func (v *flagValues) set(p *parameter, val string) error {
+	current, ok := v.values[p]
+	if !ok {
+		v.params = append(v.params, p)
+	}
+	switch {
+	case p.isMap:
+		values, _ := current.(map[string]interface{})
+		if values == nil {
+			values = make(map[string]interface{})
+		}
+		for _, s := range splitList(val) {
+			k, pv, err := p.parseEntry(s)
+			if err != nil {
+				return err
+			}
+			values[k] = pv
+		}
+		v.values[p] = values
+	case p.slice:
+		values, _ := current.([]interface{})
+		for _, s := range splitList(val) {
+			pv, err := p.parseValue(s)
+			if err != nil {
+				return err
+			}
+			values = append(values, pv)
+		}
+		v.values[p] = values
+	default:
+		pv, err := p.parseValue(val)
 		if err != nil {
 			return err
 		}
+		v.values[p] = pv
 	}
-	if err := fs.Parse(args); err != nil {
-		return err
+	return nil
+}
+
+// split splits the positional arguments at the first --. The arguments
+// after -- aren't bound to a parameter and are kept in extra.
+func (v *flagValues) split(args []string) []string {
+	for i, arg := range args {
+		if arg == "--" {
+			v.extra = args[i+1:]
+			return args[:i]
+		}
 	}
+	return args
+}
 
-	// Check for environment overrides
-	if envOverride {
-		for i, p := range params.params {
-			val, ok := os.LookupEnv(p.envName())
-			if ok {
-				if err := params.params[i].SetValueAsString(val); err != nil {
-					return err
-				}
+// errFlagHelp is returned by parseArgs when -h or --help is used
+var errFlagHelp = errors.New("help requested")
+
+// parseArgs parses the flags for a single command. Parsing stops at the first
+// argument that isn't a flag or after --. It returns the rest of the arguments
+// and true if the flags ended with --.
+func (t *flagTable) parseArgs(args []string, values *flagValues) ([]string, bool, error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return args[i+1:], true, nil
+		case len(arg) < 2 || arg[0] != '-':
+			return args[i:], false, nil
+		}
+		// Long flags can be used with one dash as well unless it's a
+		// group of short flags
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		value := ""
+		hasValue := false
+		if n := strings.Index(name, "="); n >= 0 {
+			name, value, hasValue = name[:n], name[n+1:], true
+		}
+		if strings.HasPrefix(arg, "--") || (len(name) > 1 && t.isLong(name)) {
+			next, err := t.setLong(name, value, hasValue, args[i+1:], values)
+			if err != nil {
+				return nil, false, err
 			}
+			i += next
+			continue
+		}
+		next, err := t.setShort(arg[1:], args[i+1:], values)
+		if err != nil {
+			return nil, false, err
 		}
+		i += next
 	}
+	return nil, false, nil
+}
 
-	// Set flags if they're specified
-	var flagsToSet []*flagDef
-	fs.Visit(func(f *flag.Flag) {
-		for i := range flagVars {
-			if flagVars[i].flagName == f.Name {
-				flagsToSet = append(flagsToSet, flagVars[i])
-				return
-			}
+// isLong returns true if the name is a long flag
+func (t *flagTable) isLong(name string) bool {
+	return t.long[name] != nil || name == "help" || (t.configFlag && name == ConfigFlag)
+}
+
+// setLong sets a long flag. It returns the number of arguments used for the
+// value.
+func (t *flagTable) setLong(name, value string, hasValue bool, rest []string, values *flagValues) (int, error) {
+	p := t.long[name]
+	if p == nil && name == "help" {
+		return 0, errFlagHelp
+	}
+	if p == nil && !(t.configFlag && name == ConfigFlag) {
+		return 0, fmt.Errorf("%w: --%s", ErrUnknownParameter, name)
+	}
+	used := 0
+	if !hasValue {
+		switch {
+		case p != nil && p.paramtype == boolType && !p.slice && !p.isMap:
+			value = "true"
+		case len(rest) > 0:
+			value = rest[0]
+			used = 1
+		default:
+			return 0, fmt.Errorf("%w: --%s", ErrMissingValue, name)
 		}
-	})
+	}
+	if p == nil {
+		values.configFile = value
+		return used, nil
+	}
+	return used, values.set(p, value)
+}
 
-	for i := range flagsToSet {
-		p := params.getParameter(flagsToSet[i].name)
+// setShort sets a group of short flags. Boolean flags can be combined and
+// the last flag in the group can have a value, ie -vqf file, -vqffile or
+// -vqf=file. It returns the number of arguments used for the value.
+func (t *flagTable) setShort(group string, rest []string, values *flagValues) (int, error) {
+	for i := 0; i < len(group); i++ {
+		name := group[i : i+1]
+		p := t.short[name]
+		if p == nil && name == "h" {
+			return 0, errFlagHelp
+		}
 		if p == nil {
+			return 0, fmt.Errorf("%w: -%s", ErrUnknownParameter, name)
+		}
+		value := strings.TrimPrefix(group[i+1:], "=")
+		if p.paramtype == boolType && !p.slice && !p.isMap {
+			if i+1 < len(group) && group[i+1] == '=' {
+				return 0, values.set(p, value)
+			}
+			if err := values.set(p, "true"); err != nil {
+				return 0, err
+			}
 			continue
 		}
-		if err := p.SetValue(flagsToSet[i].flagValue()); err != nil {
+		if value != "" {
+			return 0, values.set(p, value)
+		}
+		if len(rest) == 0 {
+			return 0, fmt.Errorf("%w: -%s", ErrMissingValue, name)
+		}
+		return 1, values.set(p, rest[0])
+	}
+	return 0, nil
+}
+
+// parseFlags parses the command line. The flags before the first command
+// name are parsed with the global flags, the flags after the command name
+// with the flags for the command and so on. It returns the selected command,
+// the values and the configuration file if configFlag is set.
+//
+// flag.ExitOnError prints the error and the help text and exits,
+// flag.PanicOnError panics and flag.ContinueOnError returns the error
+// without printing anything.
+func parseFlags(params *configParameters, args []string, opt flag.ErrorHandling, configFlag bool) (*Command, *flagValues, error) {
+	cmd := ""
+	values := &flagValues{values: make(map[*parameter]interface{})}
+	for {
+		table, err := newFlagTable(params, cmd, configFlag)
+		if err != nil {
+			return nil, nil, err
+		}
+		rest, terminated, err := table.parseArgs(args, values)
+		if err != nil {
+			return &Command{Name: cmd}, nil, flagError(params, cmd, opt, err)
+		}
+		// The next argument is a command unless the flags end with --
+		if len(rest) == 0 || terminated {
+			args = rest
+			break
+		}
+		next := rest[0]
+		if cmd != "" {
+			next = cmd + " " + next
+		}
+		if params.getCommand(next) == nil {
+			args = values.split(rest)
+			break
+		}
+		cmd = next
+		args = rest[1:]
+	}
+	return &Command{Name: cmd, Args: args}, values, nil
+}
+
+// flagError handles errors on the command line
+func flagError(params *configParameters, cmd string, opt flag.ErrorHandling, err error) error {
+	if err == errFlagHelp {
+		err = ErrHelp
+	}
+	switch opt {
+	case flag.ExitOnError:
+		if err != ErrHelp {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		(&Usage{Command: cmd}).print(os.Stderr, params)
+		if err == ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+// newFlagWithErrorHandling parses the command line and optionally the
+// environment variables. The command line overrides the environment.
+func newFlagWithErrorHandling(config interface{}, args []string, opt flag.ErrorHandling, envOverride bool) error {
+	flags := &flagSource{args: args, errorHandling: opt}
+	if envOverride {
+		return NewLoader(EnvSource(), flags).Load(config)
+	}
+	return NewLoader(flags).Load(config)
+}
+
+// flagSource reads values from the command line
+type flagSource struct {
+	args          []string
+	errorHandling flag.ErrorHandling
+	configFlag    bool
+}
+
+// FlagSource returns a source that reads the parameters from the command
+// line arguments. Errors are returned, ie the process doesn't exit if the
+// command line is invalid. ErrHelp is returned if -h or --help is used.
+func FlagSource(args []string) Source {
+	return &flagSource{args: args, errorHandling: flag.ContinueOnError}
+}
+
+func (s *flagSource) Name() string {
+	return SourceFlag
+}
+
+// Load parses the command line and sets the values
+func (s *flagSource) Load(set *ParameterSet) error {
+	params := set.params
+	cmd, values, err := parseFlags(params, s.args, s.errorHandling, s.configFlag)
+	if cmd != nil {
+		params.command = cmd
+	}
+	if err != nil {
+		return err
+	}
+	for _, p := range values.params {
+		if err := p.SetValue(values.values[p]); err != nil {
 			return err
 		}
+		p.source = set.source
+	}
+	args, err := params.bindArguments(cmd.Name, cmd.Args, set.source)
+	if err != nil {
+		return err
 	}
-	params.AssignValues(config)
-	return params.Validate()
+	cmd.Args = append(args, values.extra...)
+	return nil
 }
@@ -16,8 +16,12 @@ package params
 //limitations under the License.
 //
 import (
+	"bytes"
+	"errors"
 	"flag"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestCommandLineParameters(t *testing.T) {
@@ -62,3 +66,180 @@ func TestInvalidFlags(t *testing.T) {
 		t.Fatal("Expected error")
 	}
 }
+
+func TestSliceFlags(t *testing.T) {
+	var cfg struct {
+		Peer    []string        `param:"desc=Peers;default=a,b"`
+		Port    []int           `param:"desc=Ports;min=1;max=65535"`
+		Timeout []time.Duration `param:"desc=Timeouts"`
+	}
+	if err := NewFlag(&cfg, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Peer) != 2 || cfg.Peer[0] != "a" || cfg.Peer[1] != "b" {
+		t.Fatalf("Default isn't set for slice: %+v", cfg)
+	}
+	if err := NewFlag(&cfg, []string{
+		"--peer", "c",
+		"--peer", "d,e",
+		"--port=80",
+		"--port=443",
+		"--timeout", "1s",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Peer) != 3 || cfg.Peer[0] != "c" || cfg.Peer[2] != "e" {
+		t.Fatalf("Peers aren't set: %+v", cfg)
+	}
+	if len(cfg.Port) != 2 || cfg.Port[0] != 80 || cfg.Port[1] != 443 {
+		t.Fatalf("Ports aren't set: %+v", cfg)
+	}
+	if len(cfg.Timeout) != 1 || cfg.Timeout[0] != time.Second {
+		t.Fatalf("Timeouts aren't set: %+v", cfg)
+	}
+	if err := NewFlag(&cfg, []string{"--port", "80", "--port", "0"}); err == nil {
+		t.Fatal("Expected error when element is out of range")
+	}
+}
+
+func TestMapFlags(t *testing.T) {
+	var cfg struct {
+		Label   map[string]string        `param:"desc=Labels"`
+		Limit   map[string]int           `param:"desc=Limits;max=10"`
+		Timeout map[string]time.Duration `param:"desc=Timeouts"`
+	}
+	if err := NewFlag(&cfg, []string{
+		"--label", "a=1",
+		"--label", "b=2,c=x=y",
+		"--limit=a=5",
+		"--timeout", "read=1s",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Label) != 3 || cfg.Label["a"] != "1" || cfg.Label["c"] != "x=y" {
+		t.Fatalf("Labels aren't set: %+v", cfg)
+	}
+	if cfg.Limit["a"] != 5 || cfg.Timeout["read"] != time.Second {
+		t.Fatalf("Maps aren't set: %+v", cfg)
+	}
+	if err := NewFlag(&cfg, []string{"--limit", "a=11"}); err == nil {
+		t.Fatal("Expected error when map value is out of range")
+	}
+	if err := newFlagWithErrorHandling(&cfg, []string{"--label", "novalue"}, flag.ContinueOnError, false); err == nil {
+		t.Fatal("Expected error when map entry has no value")
+	}
+}
+
+func TestParseFlag(t *testing.T) {
+	config := parameters{}
+	if err := ParseFlag(&config, []string{"--unknown-flag"}); err == nil {
+		t.Fatal("Expected error with unknown flag")
+	}
+	if err := ParseFlag(&config, []string{"--my-val", "foo"}); err == nil {
+		t.Fatal("Expected error with invalid value")
+	}
+	for _, arg := range []string{"-h", "--help", "-help"} {
+		if err := ParseFlag(&config, []string{arg}); err != ErrHelp {
+			t.Fatalf("Expected ErrHelp for %s but got %v", arg, err)
+		}
+		if err := ParseEnvFlag(&config, []string{arg}); err != ErrHelp {
+			t.Fatalf("Expected ErrHelp for %s but got %v", arg, err)
+		}
+	}
+	if err := ParseEnvFlag(&config, []string{"--my-val", "12"}); err != nil || config.MyVal != 12 {
+		t.Fatalf("Expected no error and value set: %v %+v", err, config)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := PrintUsage(buf, &config); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "-http-endpoint") || !strings.Contains(buf.String(), "Server endpoint") {
+		t.Fatalf("Usage doesn't contain the parameters:\n%s", buf.String())
+	}
+}
+
+func TestShortFlagsAndAliases(t *testing.T) {
+	type shortConfig struct {
+		Verbose bool          `param:"desc=Verbose;short=v"`
+		Quiet   bool          `param:"desc=Quiet;short=q"`
+		File    string        `param:"desc=File;short=f;alias=input,in-file"`
+		Count   int           `param:"desc=Count;short=n"`
+		Timeout time.Duration `param:"desc=Timeout"`
+		Peer    []string      `param:"desc=Peers;short=p"`
+	}
+	var cfg shortConfig
+	if err := ParseFlag(&cfg, []string{"-vqf", "a.txt", "-n5", "-p", "a", "-p=b", "--timeout", "1s"}); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Verbose || !cfg.Quiet || cfg.File != "a.txt" || cfg.Count != 5 || cfg.Timeout != time.Second {
+		t.Fatalf("Short flags aren't set: %+v", cfg)
+	}
+	if len(cfg.Peer) != 2 || cfg.Peer[1] != "b" {
+		t.Fatalf("Repeated short flags aren't set: %+v", cfg)
+	}
+
+	cfg = shortConfig{}
+	if err := ParseFlag(&cfg, []string{"--input=b.txt", "--verbose=false", "-q=true", "--count", "7", "-vfc.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.File != "c.txt" || !cfg.Verbose || !cfg.Quiet || cfg.Count != 7 {
+		t.Fatalf("Flags aren't set: %+v", cfg)
+	}
+	if err := ParseFlag(&cfg, []string{"--in-file", "d.txt"}); err != nil || cfg.File != "d.txt" {
+		t.Fatalf("Alias isn't set: %+v %v", cfg, err)
+	}
+
+	// Long flags with a single dash still work
+	if err := ParseFlag(&cfg, []string{"-timeout=2s", "-verbose"}); err != nil || cfg.Timeout != 2*time.Second || !cfg.Verbose {
+		t.Fatalf("Single dash long flags aren't set: %+v %v", cfg, err)
+	}
+
+	if err := ParseFlag(&cfg, []string{"-x"}); !errors.Is(err, ErrUnknownParameter) {
+		t.Fatalf("Expected unknown flag but got %v", err)
+	}
+	if err := ParseFlag(&cfg, []string{"-vf"}); !errors.Is(err, ErrMissingValue) {
+		t.Fatalf("Expected missing value but got %v", err)
+	}
+	if err := ParseFlag(&cfg, []string{"--count"}); !errors.Is(err, ErrMissingValue) {
+		t.Fatalf("Expected missing value but got %v", err)
+	}
+	if err := ParseFlag(&cfg, []string{"--count", "x"}); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("Expected invalid value but got %v", err)
+	}
+	if err := ParseFlag(&cfg, []string{"-h"}); err != ErrHelp {
+		t.Fatalf("Expected ErrHelp but got %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := PrintUsage(buf, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "  -f, --file string\n        File (alias: --input, --in-file)\n") {
+		t.Fatalf("Short flag or alias isn't in help text:\n%s", buf.String())
+	}
+}
+
+func TestInvalidShortFlags(t *testing.T) {
+	var long struct {
+		Verbose bool `param:"desc=Verbose;short=vv"`
+	}
+	if err := ParseFlag(&long, []string{}); err == nil {
+		t.Fatal("Expected error with long short flag")
+	}
+	var duplicate struct {
+		Verbose bool `param:"desc=Verbose;short=v"`
+		Version bool `param:"desc=Version;short=v"`
+	}
+	var tagErr *TagError
+	if err := ParseFlag(&duplicate, []string{}); !errors.As(err, &tagErr) {
+		t.Fatalf("Expected TagError with duplicate short flags but got %v", err)
+	}
+	var alias struct {
+		Verbose bool `param:"desc=Verbose;alias=quiet"`
+		Quiet   bool `param:"desc=Quiet"`
+	}
+	if err := ParseFlag(&alias, []string{}); !errors.As(err, &tagErr) {
+		t.Fatalf("Expected TagError with duplicate alias but got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NewINIFile populates a config struct with values from an INI file.
+// Sections are nested structs and the keys use the same naming as JSON files
+// (see NewFile). Lists are comma-separated:
+//
+//  logType = plain
+//  peers = a.example.com, b.example.com
+//
+//  [http]
+//  endpoint = localhost:1234
+//
+// Map parameters can be set with a section of their own or with a single
+// comma-separated list of key=value pairs.
+func NewINIFile(config interface{}, reader io.Reader, opts ...FileOption) error {
+	return NewLoader(INISource(reader, opts...)).Load(config)
+}
+
+// fileEntry is a single key and value from an INI or properties file
+type fileEntry struct {
+	key   string
+	value string
+	line  int
+}
+
+// iniSource reads values from an INI file
+type iniSource struct {
+	reader  io.Reader
+	options fileOptions
+}
+
+// INISource returns a source that reads the parameters from an INI file.
+// See NewINIFile for the format.
+func INISource(reader io.Reader, opts ...FileOption) Source {
+	return &iniSource{reader: reader, options: newFileOptions(opts)}
+}
+
+func (i *iniSource) Name() string {
+	return SourceFile
+}
+
+func (i *iniSource) Load(set *ParameterSet) error {
+	if i.reader == nil {
+		return ErrNilReader
+	}
+	entries, err := parseINI(i.reader)
+	if err != nil {
+		return err
+	}
+	unknown, err := setEntries(set, entries)
+	if err != nil {
+		return err
+	}
+	return i.options.checkUnknown(set.params, unknown)
+}
+
+// parseINI reads the entries in an INI file. Comments start with ; or # and
+// keys in a section are prefixed with the section name.
+func parseINI(reader io.Reader) ([]fileEntry, error) {
+	var entries []fileEntry
+	section := ""
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == ';' || text[0] == '#' {
+			continue
+		}
+		if text[0] == '[' {
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("line %d: invalid section %q", line, text)
+			}
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			continue
+		}
+		n := strings.IndexAny(text, "=:")
+		if n < 1 {
+			return nil, fmt.Errorf("line %d: expected key = value but got %q", line, text)
+		}
+		key := strings.TrimSpace(text[:n])
+		if section != "" {
+			key = section + "." + key
+		}
+		entries = append(entries, fileEntry{key: key, value: unquote(strings.TrimSpace(text[n+1:])), line: line})
+	}
+	return entries, scanner.Err()
+}
+
+// unquote removes the quotes around a value
+func unquote(val string) string {
+	if len(val) < 2 || val[0] != val[len(val)-1] {
+		return val
+	}
+	switch val[0] {
+	case '"':
+		if s, err := strconv.Unquote(val); err == nil {
+			return s
+		}
+		return val[1 : len(val)-1]
+	case '\'':
+		return val[1 : len(val)-1]
+	}
+	return val
+}
+
+// setEntries sets the parameters from a list of entries. The values are set
+// just like environment variables. If the prefix of a key is a map parameter
+// the key is an entry in the map, ie labels.key = value sets the "key" entry
+// in the Labels map. The keys that don't match a parameter are returned.
+func setEntries(set *ParameterSet, entries []fileEntry) ([]string, error) {
+	var unknown []string
+	maps := make(map[*parameter]map[string]interface{})
+	for _, e := range entries {
+		if para := set.params.getParameter(e.key); para != nil {
+			if err := para.SetValueAsString(e.value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", e.line, err)
+			}
+			para.source = set.source
+			continue
+		}
+		var para *parameter
+		n := strings.LastIndex(e.key, ".")
+		if n >= 0 {
+			para = set.params.getParameter(e.key[:n])
+		}
+		if para == nil || !para.isMap {
+			unknown = append(unknown, e.key)
+			continue
+		}
+		v, err := para.parseValue(e.value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", e.line, err)
+		}
+		if maps[para] == nil {
+			maps[para] = make(map[string]interface{})
+		}
+		maps[para][e.key[n+1:]] = v
+	}
+	for para, values := range maps {
+		if err := para.SetValue(values); err != nil {
+			return nil, err
+		}
+		para.source = set.source
+	}
+	return unknown, nil
+}
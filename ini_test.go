@@ -0,0 +1,116 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type fileFormatConfig struct {
+	LogType string            `param:"desc=Log type;default=plain"`
+	Peers   []string          `param:"desc=Peers"`
+	Labels  map[string]string `param:"desc=Labels"`
+	HTTP    struct {
+		Endpoint string        `param:"desc=Endpoint"`
+		Timeout  time.Duration `param:"desc=Timeout;default=1s"`
+	}
+}
+
+func TestINIFile(t *testing.T) {
+	var cfg fileFormatConfig
+	if err := NewINIFile(&cfg, nil); err == nil {
+		t.Fatal("Expected error with nil reader")
+	}
+
+	file := `
+; comment
+logType = fancy
+peers = a, b
+unknown = ignored
+
+[http]
+# another comment
+endpoint = "localhost:1234"
+timeout: 10s
+
+[labels]
+team = backend
+env = 'prod'
+`
+	if err := NewINIFile(&cfg, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LogType != "fancy" || len(cfg.Peers) != 2 || cfg.Peers[1] != "b" {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+	if cfg.HTTP.Endpoint != "localhost:1234" || cfg.HTTP.Timeout != 10*time.Second {
+		t.Fatalf("Section isn't set: %+v", cfg.HTTP)
+	}
+	if len(cfg.Labels) != 2 || cfg.Labels["team"] != "backend" || cfg.Labels["env"] != "prod" {
+		t.Fatalf("Map isn't set: %+v", cfg.Labels)
+	}
+
+	err := NewINIFile(&cfg, strings.NewReader("[http]\ntimeout = soon\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatalf("Expected error with line number but got %v", err)
+	}
+	if err := NewINIFile(&cfg, strings.NewReader("[http\n")); err == nil {
+		t.Fatal("Expected error with invalid section")
+	}
+	if err := NewINIFile(&cfg, strings.NewReader("novalue\n")); err == nil {
+		t.Fatal("Expected error with missing value")
+	}
+}
+
+func TestPropertiesFile(t *testing.T) {
+	var cfg fileFormatConfig
+	if err := NewPropertiesFile(&cfg, nil); err == nil {
+		t.Fatal("Expected error with nil reader")
+	}
+
+	file := `
+# comment
+! another comment
+logType fancy
+peers = a, \
+        b
+http.endpoint : localhost\:1234
+http.timeout=10s
+labels.team = back\u0065nd
+`
+	if err := NewPropertiesFile(&cfg, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LogType != "fancy" || len(cfg.Peers) != 2 || cfg.Peers[1] != "b" {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+	if cfg.HTTP.Endpoint != "localhost:1234" || cfg.HTTP.Timeout != 10*time.Second {
+		t.Fatalf("Dotted keys aren't set: %+v", cfg.HTTP)
+	}
+	if cfg.Labels["team"] != "backend" {
+		t.Fatalf("Map isn't set: %+v", cfg.Labels)
+	}
+
+	err := NewPropertiesFile(&cfg, strings.NewReader("logType=a\nhttp.timeout=soon\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatalf("Expected error with line number but got %v", err)
+	}
+	if err := NewPropertiesFile(&cfg, strings.NewReader("key=\\u12\n")); err == nil {
+		t.Fatal("Expected error with invalid escape")
+	}
+}
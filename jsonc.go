@@ -0,0 +1,166 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"fmt"
+)
+
+// JSONC makes FileSource and NewFile accept JSON with comments. Line (//)
+// and block (/* */) comments, trailing commas and unquoted keys are allowed:
+//
+//  {
+//      // Some of the clients are slow
+//      timeout: "90s",
+//      peers: ["a.example.com", "b.example.com",],
+//  }
+//
+// Errors are reported with the position in the original file. This is a
+// subset of JSON5. Single quoted strings and JSON5 numbers like 0x10, +1 and
+// .5 aren't supported.
+func JSONC() FileOption {
+	return func(o *fileOptions) {
+		o.jsonc = true
+	}
+}
+
+// convertJSONC converts JSONC to JSON. Comments and trailing commas are
+// replaced with white space so the offsets stay the same. Quotes are added
+// around unquoted keys and the offsets of the inserted quotes in the
+// converted JSON are returned.
+func convertJSONC(data []byte) ([]byte, []int64, error) {
+	out := make([]byte, 0, len(data))
+	var inserted []int64
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			end := stringEnd(data, i)
+			out = append(out, data[i:end]...)
+			i = end - 1
+		case c == '/' && i+1 < len(data) && (data[i+1] == '/' || data[i+1] == '*'):
+			end, err := commentEnd(data, i)
+			if err != nil {
+				return nil, nil, err
+			}
+			out = append(out, blank(data[i:end])...)
+			i = end - 1
+		case c == ',':
+			next, err := nextToken(data, i+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			if next < len(data) && (data[next] == '}' || data[next] == ']') {
+				c = ' '
+			}
+			out = append(out, c)
+		case isIdentStart(c):
+			end := i
+			for end < len(data) && (isIdentStart(data[end]) || (data[end] >= '0' && data[end] <= '9')) {
+				end++
+			}
+			next, err := nextToken(data, end)
+			if err != nil {
+				return nil, nil, err
+			}
+			if next < len(data) && data[next] == ':' {
+				inserted = append(inserted, int64(len(out)))
+				out = append(out, '"')
+				out = append(out, data[i:end]...)
+				inserted = append(inserted, int64(len(out)))
+				out = append(out, '"')
+			} else {
+				out = append(out, data[i:end]...)
+			}
+			i = end - 1
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, inserted, nil
+}
+
+// isIdentStart returns true for the characters that can start an unquoted key
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// stringEnd returns the offset after the string starting at start. Errors in
+// the string are reported by the JSON decoder.
+func stringEnd(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// commentEnd returns the offset after the comment starting at start
+func commentEnd(data []byte, start int) (int, error) {
+	if data[start+1] == '/' {
+		for i := start + 2; i < len(data); i++ {
+			if data[i] == '\n' {
+				return i, nil
+			}
+		}
+		return len(data), nil
+	}
+	for i := start + 2; i+1 < len(data); i++ {
+		if data[i] == '*' && data[i+1] == '/' {
+			return i + 2, nil
+		}
+	}
+	line, col := position(data, int64(start))
+	return 0, fmt.Errorf("line %d, column %d: unterminated comment", line, col)
+}
+
+// nextToken returns the offset of the next character that isn't white space
+// or a comment
+func nextToken(data []byte, start int) (int, error) {
+	for i := start; i < len(data); i++ {
+		switch data[i] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '/':
+			if i+1 < len(data) && (data[i+1] == '/' || data[i+1] == '*') {
+				end, err := commentEnd(data, i)
+				if err != nil {
+					return 0, err
+				}
+				i = end - 1
+				continue
+			}
+		}
+		return i, nil
+	}
+	return len(data), nil
+}
+
+// blank replaces everything except line breaks with spaces
+func blank(comment []byte) []byte {
+	ret := make([]byte, len(comment))
+	for i, c := range comment {
+		ret[i] = ' '
+		if c == '\n' {
+			ret[i] = '\n'
+		}
+	}
+	return ret
+}
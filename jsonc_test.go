@@ -0,0 +1,67 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONC(t *testing.T) {
+	var cfg struct {
+		Timeout time.Duration `param:"desc=Timeout"`
+		Peers   []string      `param:"desc=Peers"`
+		HTTP    struct {
+			Endpoint string `param:"desc=Endpoint"`
+		}
+	}
+	file := `{
+		// The clients are slow
+		timeout: "90s",
+		/* Two peers
+		   for now */
+		peers: ["a", "b // not a comment",],
+		"http": {
+			endpoint: "localhost:1234", // trailing comment
+		},
+	}`
+	if err := NewFile(&cfg, strings.NewReader(file)); err == nil {
+		t.Fatal("Expected error without the JSONC option")
+	}
+	if err := NewFile(&cfg, strings.NewReader(file), JSONC()); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 90*time.Second || len(cfg.Peers) != 2 || cfg.Peers[1] != "b // not a comment" {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+	if cfg.HTTP.Endpoint != "localhost:1234" {
+		t.Fatalf("Nested value isn't set: %+v", cfg)
+	}
+
+	err := NewFile(&cfg, strings.NewReader("{\n  timeout: \"1s\",\n  peers: [\"a\" \"b\"]\n}"), JSONC())
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3, column 15:") {
+		t.Fatalf("Expected error with position but got %v", err)
+	}
+	err = NewFile(&cfg, strings.NewReader("{\n  /* unterminated\n}"), JSONC())
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2, column 3:") {
+		t.Fatalf("Expected error with position but got %v", err)
+	}
+	err = NewFile(&cfg, strings.NewReader("{timeout: \"1s\"} // done\n{peers: []}"), JSONC())
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2, column 1:") {
+		t.Fatalf("Expected error with data after the object but got %v", err)
+	}
+}
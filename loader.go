@@ -0,0 +1,176 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"errors"
+	"fmt"
+)
+
+// Source is a source of parameter values for a Loader. The built-in sources
+// are FileSource, EnvSource and FlagSource but you can write your own
+// sources, f.e. to read parameters from a database table or a secret store.
+// LookupSource is the easiest way to make a new source.
+type Source interface {
+	// Name returns the name of the source. The name is reported as the
+	// source in ValidationError.
+	Name() string
+
+	// Load sets the values from the source.
+	Load(set *ParameterSet) error
+}
+
+// ErrUnknownParameter is returned by ParameterSet when the parameter doesn't
+// exist
+var ErrUnknownParameter = errors.New("unknown parameter")
+
+// Parameter describes a single parameter. Sources use this to look up values.
+type Parameter struct {
+	Name        string // The parameter name, ie HTTP.Endpoint
+	Flag        string // The command line flag, ie http-endpoint
+	Env         string // The environment variable, ie HTTP_ENDPOINT
+	Description string // The description from the tag
+	Default     string // The default value from the tag
+	Required    bool   // True if the parameter is required
+}
+
+// ParameterSet is the set of parameters for a configuration. Sources set
+// the values in the set when they are loaded.
+type ParameterSet struct {
+	params *configParameters
+	source string
+}
+
+// Parameters returns the list of parameters in the set
+func (s *ParameterSet) Parameters() []Parameter {
+	ret := make([]Parameter, len(s.params.params))
+	for i := range s.params.params {
+		p := &s.params.params[i]
+		ret[i] = Parameter{
+			Name:        p.name,
+			Flag:        p.flagName(),
+			Env:         p.envName(),
+			Description: p.description,
+			Default:     p.defaultValue,
+			Required:    p.required,
+		}
+	}
+	return ret
+}
+
+// Set sets the value of a parameter from its string representation. Lists
+// are comma-separated and maps are comma-separated key=value pairs, just like
+// environment variables. The name is case insensitive.
+func (s *ParameterSet) Set(name, value string) error {
+	p := s.params.getParameter(name)
+	if p == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownParameter, name)
+	}
+	if err := p.SetValueAsString(value); err != nil {
+		return err
+	}
+	p.source = s.source
+	return nil
+}
+
+// lookupSource is a source that uses a lookup function
+type lookupSource struct {
+	name   string
+	lookup func(p Parameter) (string, bool)
+}
+
+// LookupSource returns a source that calls the lookup function for each of
+// the parameters. The function returns the value and true if the parameter
+// is set. The name is used as the source name.
+func LookupSource(name string, lookup func(p Parameter) (string, bool)) Source {
+	return &lookupSource{name: name, lookup: lookup}
+}
+
+func (l *lookupSource) Name() string {
+	return l.name
+}
+
+func (l *lookupSource) Load(set *ParameterSet) error {
+	for _, p := range set.Parameters() {
+		v, ok := l.lookup(p)
+		if !ok {
+			continue
+		}
+		if err := set.Set(p.Name, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Loader loads a configuration from an ordered list of sources. The defaults
+// from the tags are applied first, then each source in turn. Values from
+// sources later in the list override values from earlier sources. The usual
+// precedence is defaults < file < environment < flags:
+//
+//  loader := params.NewLoader(
+//      params.FileSource(f),
+//      params.EnvSource(),
+//      params.FlagSource(os.Args[1:]))
+//  if err := loader.Load(&config); err != nil {
+//      ...
+//  }
+//
+// Swap the order of the sources if you want f.e. the configuration file to
+// override the environment variables.
+type Loader struct {
+	sources []Source
+}
+
+// NewLoader creates a new loader with the sources in increasing order of
+// precedence.
+func NewLoader(sources ...Source) *Loader {
+	return &Loader{sources: sources}
+}
+
+// Load populates the configuration from the sources and validates it.
+func (l *Loader) Load(config interface{}) error {
+	_, err := l.load(config)
+	return err
+}
+
+// LoadCommand works like Load for configurations with commands. It returns the
+// command selected on the command line. See ParseCommand.
+func (l *Loader) LoadCommand(config interface{}) (*Command, error) {
+	params, err := l.load(config)
+	if params == nil || params.command == nil {
+		return &Command{}, err
+	}
+	return params.command, err
+}
+
+func (l *Loader) load(config interface{}) (*configParameters, error) {
+	params, err := newConfigParameters(config)
+	if err != nil {
+		return nil, err
+	}
+	set := &ParameterSet{params: params}
+	for _, s := range l.sources {
+		set.source = s.Name()
+		if err := s.Load(set); err != nil {
+			return params, err
+		}
+	}
+	if err := params.AssignValues(config); err != nil {
+		return params, err
+	}
+	return params, params.Validate()
+}
@@ -0,0 +1,140 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type precedenceConfig struct {
+	PrecAlpha string `param:"default=default"`
+	PrecBeta  string `param:"default=default"`
+	PrecGamma string `param:"default=default"`
+	PrecDelta string `param:"default=default"`
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	os.Setenv("PREC_BETA", "env")
+	os.Setenv("PREC_GAMMA", "env")
+	defer os.Unsetenv("PREC_BETA")
+	defer os.Unsetenv("PREC_GAMMA")
+	file := `{"precAlpha": "file", "precBeta": "file", "precGamma": "file"}`
+
+	var cfg precedenceConfig
+	if err := NewLoader(
+		FileSource(strings.NewReader(file)),
+		EnvSource(),
+		FlagSource([]string{"--prec-gamma", "flag"})).Load(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PrecAlpha != "file" || cfg.PrecBeta != "env" || cfg.PrecGamma != "flag" || cfg.PrecDelta != "default" {
+		t.Fatalf("Expected defaults < file < env < flags: %+v", cfg)
+	}
+
+	cfg = precedenceConfig{}
+	if err := NewLoader(
+		EnvSource(),
+		FileSource(strings.NewReader(file))).Load(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PrecAlpha != "file" || cfg.PrecBeta != "file" || cfg.PrecGamma != "file" || cfg.PrecDelta != "default" {
+		t.Fatalf("Expected file to override env: %+v", cfg)
+	}
+
+	cfg = precedenceConfig{}
+	if err := NewLoader().Load(&cfg); err != nil || cfg.PrecAlpha != "default" {
+		t.Fatalf("Expected defaults only: %v %+v", err, cfg)
+	}
+	if err := NewLoader(FlagSource([]string{"-h"})).Load(&cfg); err != ErrHelp {
+		t.Fatalf("Expected ErrHelp from flag source but got %v", err)
+	}
+}
+
+// mapSource is a custom source for testing
+type mapSource map[string]string
+
+func (m mapSource) Name() string {
+	return "map"
+}
+
+func (m mapSource) Load(set *ParameterSet) error {
+	for k, v := range m {
+		if err := set.Set(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCustomSource(t *testing.T) {
+	var cfg struct {
+		Name    string   `param:"desc=Name;required"`
+		Workers int      `param:"desc=Workers;max=10"`
+		Peers   []string `param:"desc=Peers"`
+		HTTP    struct {
+			Endpoint string `param:"desc=Endpoint;default=:8080"`
+		}
+	}
+	src := mapSource{"name": "db", "workers": "4", "peers": "a,b", "http.endpoint": ":80"}
+	if err := NewLoader(src, FlagSource([]string{"--workers", "5"})).Load(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "db" || cfg.Workers != 5 || len(cfg.Peers) != 2 || cfg.HTTP.Endpoint != ":80" {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+
+	err := NewLoader(mapSource{"name": "db", "workers": "11"}).Load(&cfg)
+	var verr ValidationErrors
+	if !errors.As(err, &verr) || verr[0].Source != "map" || verr[0].Value != "11" {
+		t.Fatalf("Expected validation error from map source but got %v", err)
+	}
+	if err := NewLoader(mapSource{"foo": "bar"}).Load(&cfg); !errors.Is(err, ErrUnknownParameter) {
+		t.Fatalf("Expected ErrUnknownParameter but got %v", err)
+	}
+	if err := NewLoader(mapSource{"workers": "x"}).Load(&cfg); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("Expected ErrInvalidValue but got %v", err)
+	}
+}
+
+func TestLookupSource(t *testing.T) {
+	var cfg struct {
+		Name string `param:"desc=Name;default=foo"`
+		HTTP struct {
+			Endpoint string `param:"desc=Endpoint;default=:8080"`
+		}
+	}
+	var seen []Parameter
+	src := LookupSource("secrets", func(p Parameter) (string, bool) {
+		seen = append(seen, p)
+		if p.Env == "HTTP_ENDPOINT" {
+			return ":443", true
+		}
+		return "", false
+	})
+	if err := NewLoader(src).Load(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "foo" || cfg.HTTP.Endpoint != ":443" {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+	if len(seen) != 2 || seen[1].Name != "HTTP.Endpoint" || seen[1].Flag != "http-endpoint" ||
+		seen[1].Default != ":8080" || seen[1].Description != "Endpoint" {
+		t.Fatalf("Unexpected parameters: %+v", seen)
+	}
+}
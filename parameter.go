@@ -16,9 +16,14 @@ package params
 //limitations under the License.
 //
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +42,14 @@ const (
 	boolType
 	durationType
 	floatType
+	timeType
+	urlType
+	ipType
+	ipNetType
+	regexpType
+	byteSizeType
+	customType
+	textType
 	invalidType
 )
 
@@ -53,17 +66,56 @@ type parameter struct {
 	options      []string
 	required     bool
 	isSet        bool
+	source       string
+	slice        bool
+	isMap        bool
+	pointer      bool
+	fieldType    reflect.Type
+	layout       string
+	minlen       string
+	maxlen       string
+	command      string
+	cmdPrefix    string
+	positional   bool
+	position     int
+	short        string
+	aliases      []string
 }
 
-// hyphenName converts name into a lowercase string with hyphens. Hyphens are
+// hyphenName converts name into a lowercase string with hyphens.
+func (p *parameter) hyphenName() string {
+	return hyphenate(p.name)
+}
+
+// flagName returns the name of the command line flag. The names of parameters
+// in commands are relative to the command.
+func (p *parameter) flagName() string {
+	return hyphenate(strings.TrimPrefix(p.name, p.cmdPrefix))
+}
+
+// usageName returns the name used on the command line, ie --http-endpoint
+// for flags and <input-file> for positional arguments
+func (p *parameter) usageName() string {
+	if p.positional {
+		return "<" + p.flagName() + ">"
+	}
+	return "--" + p.flagName()
+}
+
+// isFlagChar returns true for the characters that can be used as short flags
+func isFlagChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// hyphenate converts name into a lowercase string with hyphens. Hyphens are
 // inserted when case transitions from LUL (as in "NameName"), UUL (as in "TLAName")
 // and LUU (as in "NameTLA")
-func (p *parameter) hyphenName() string {
+func hyphenate(name string) string {
 	var ret []rune
 	prevChar := 'X'
 	prevPrevChar := prevChar
 	changes := 0
-	for i, ch := range strings.Replace(p.name, ".", "-", -1) {
+	for i, ch := range strings.Replace(name, ".", "-", -1) {
 		// first char is always included
 		if i < 2 {
 			ret = append(ret, ch)
@@ -91,17 +143,31 @@ func (p *parameter) hyphenName() string {
 func (p *parameter) envName() string {
 	return strings.Replace(strings.ToUpper(p.hyphenName()), "-", "_", -1)
 }
+
+// toInternalType returns the internal type for a field value. All sizes of
+// integers and floats are supported.
 func toInternalType(value interface{}) internalType {
 	if _, ok := value.(time.Duration); ok {
 		return durationType
 	}
-	if _, ok := value.(uint); ok {
+	switch value.(type) {
+	case ByteSize:
+		return byteSizeType
+	case time.Time:
+		return timeType
+	case url.URL:
+		return urlType
+	case net.IP:
+		return ipType
+	case net.IPNet:
+		return ipNetType
+	case regexp.Regexp:
+		return regexpType
+	case uint, uint8, uint16, uint32, uint64:
 		return uintType
-	}
-	if _, ok := value.(int); ok {
+	case int, int8, int16, int32, int64:
 		return intType
-	}
-	if _, ok := value.(float64); ok {
+	case float32, float64:
 		return floatType
 	}
 	if _, ok := value.(bool); ok {
@@ -110,75 +176,256 @@ func toInternalType(value interface{}) internalType {
 	if _, ok := value.(string); ok {
 		return stringType
 	}
-	return invalidType
+	return customInternalType(reflect.TypeOf(value))
 }
 
-func (p *parameter) SetValueAsString(val string) error {
+// validType checks if the value has the same type as the parameter (or its
+// elements). Integers and floats are stored as int64, uint64 and float64
+// regardless of the field size.
+func (p *parameter) validType(value interface{}) bool {
+	switch p.paramtype {
+	case intType:
+		_, ok := value.(int64)
+		return ok
+	case uintType:
+		_, ok := value.(uint64)
+		return ok
+	case floatType:
+		_, ok := value.(float64)
+		return ok
+	case customType, textType:
+		return reflect.TypeOf(value) == p.fieldType
+	}
+	return toInternalType(value) == p.paramtype
+}
+
+// isParsed returns true for types that are set through parseValue on the
+// command line and from strings in configuration files
+func (t internalType) isParsed() bool {
+	switch t {
+	case intType, uintType, floatType, timeType, urlType, ipType, ipNetType, regexpType, byteSizeType, customType, textType:
+		return true
+	}
+	return false
+}
 
+// isOrdered returns true for types that can be used with min and max
+func (t internalType) isOrdered() bool {
+	switch t {
+	case intType, uintType, floatType, byteSizeType, durationType, timeType:
+		return true
+	}
+	return false
+}
+
+// parseError returns a ParseError for a value that can't be parsed
+func (p *parameter) parseError(val string, err error) error {
+	msg := fmt.Sprintf("invalid value for field %s: %s", p.name, val)
+	if err != nil {
+		msg = fmt.Sprintf("%s (%v)", msg, err)
+	}
+	return &ParseError{Name: p.name, Value: val, Err: err, msg: msg}
+}
+
+// numberError returns the error for integers and floats that can't be parsed
+func (p *parameter) numberError(val string, err error) error {
+	msg := fmt.Sprintf("invalid value for field %s: %s", p.name, val)
+	if errors.Is(err, strconv.ErrRange) {
+		msg = fmt.Sprintf("value %s overflows %v for --%s", val, p.fieldType, p.flagName())
+	}
+	return &ParseError{Name: p.name, Value: val, Err: err, msg: msg}
+}
+
+// parseValue parses a single value for the parameter. For slices this is a
+// single element of the list.
+func (p *parameter) parseValue(val string) (interface{}, error) {
 	switch p.paramtype {
 	case stringType:
-		p.value = val
+		return val, nil
 	case uintType:
-		v, err := strconv.ParseInt(val, 10, 64)
-		if err != nil || v < 0 {
-			return fmt.Errorf("invalid value for field %s: %s", p.name, val)
+		v, err := strconv.ParseUint(val, 10, p.fieldType.Bits())
+		if err != nil {
+			return nil, p.numberError(val, err)
 		}
-		p.value = uint(v)
-
+		return v, nil
 	case intType:
-		v, err := strconv.ParseInt(val, 10, 64)
+		v, err := strconv.ParseInt(val, 10, p.fieldType.Bits())
 		if err != nil {
-			return fmt.Errorf("invalid value for field %s: %s", p.name, val)
+			return nil, p.numberError(val, err)
 		}
-		p.value = int(v)
+		return v, nil
 	case boolType:
 		v, err := strconv.ParseBool(val)
 		if err != nil {
-			return fmt.Errorf("invalid value for field %s: %s", p.name, val)
+			return nil, p.parseError(val, nil)
 		}
-		p.value = v
+		return v, nil
 	case durationType:
 		v, err := time.ParseDuration(val)
 		if err != nil {
-			return fmt.Errorf("invalid value for field %s: %s", p.name, val)
+			return nil, p.parseError(val, nil)
 		}
-		p.value = v
+		return v, nil
 	case floatType:
-		v, err := strconv.ParseFloat(val, 64)
+		v, err := strconv.ParseFloat(val, p.fieldType.Bits())
 		if err != nil {
-			return fmt.Errorf("invalid value for field %s: %s", p.name, val)
+			return nil, p.numberError(val, err)
 		}
-		p.value = v
-
+		return v, nil
+	case timeType:
+		layout := time.RFC3339
+		if p.layout != "" {
+			layout = p.layout
+		}
+		v, err := time.Parse(layout, val)
+		if err != nil {
+			return nil, p.parseError(val, err)
+		}
+		return v, nil
+	case urlType:
+		v, err := url.Parse(val)
+		if err != nil {
+			return nil, p.parseError(val, err)
+		}
+		return *v, nil
+	case ipType:
+		v := net.ParseIP(val)
+		if v == nil {
+			return nil, p.parseError(val, nil)
+		}
+		return v, nil
+	case ipNetType:
+		_, v, err := net.ParseCIDR(val)
+		if err != nil {
+			return nil, p.parseError(val, err)
+		}
+		return *v, nil
+	case regexpType:
+		v, err := regexp.Compile(val)
+		if err != nil {
+			return nil, p.parseError(val, err)
+		}
+		return *v, nil
+	case byteSizeType:
+		v, err := ParseByteSize(val)
+		if err != nil {
+			return nil, p.parseError(val, err)
+		}
+		return v, nil
+	case customType, textType:
+		v, err := parseCustomValue(p.fieldType, val)
+		if err != nil {
+			return nil, p.parseError(val, err)
+		}
+		return v, nil
 	default:
-		return fmt.Errorf("unknown parameter type: %v", p.paramtype)
+		return nil, fmt.Errorf("unknown parameter type: %v", p.paramtype)
+	}
+}
+
+// splitList splits a comma-separated list of values. An empty string is an
+// empty list.
+func splitList(val string) []string {
+	if strings.TrimSpace(val) == "" {
+		return []string{}
+	}
+	ret := strings.Split(val, ",")
+	for i := range ret {
+		ret[i] = strings.TrimSpace(ret[i])
+	}
+	return ret
+}
+
+// parseEntry parses a single key=value entry for map parameters
+func (p *parameter) parseEntry(val string) (string, interface{}, error) {
+	kv := strings.SplitN(val, "=", 2)
+	if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+		return "", nil, p.parseError(val, nil)
+	}
+	v, err := p.parseValue(strings.TrimSpace(kv[1]))
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(kv[0]), v, nil
+}
+
+// SetValueAsString sets the parameter value from a string. Slices are
+// comma-separated lists and maps are comma-separated key=value pairs.
+func (p *parameter) SetValueAsString(val string) error {
+	if p.isMap {
+		values := make(map[string]interface{})
+		for _, s := range splitList(val) {
+			k, v, err := p.parseEntry(s)
+			if err != nil {
+				return err
+			}
+			values[k] = v
+		}
+		p.value = values
+		p.isSet = true
+		return nil
+	}
+	if !p.slice {
+		v, err := p.parseValue(val)
+		if err != nil {
+			return err
+		}
+		p.value = v
+		p.isSet = true
+		return nil
+	}
+	values := make([]interface{}, 0)
+	for _, s := range splitList(val) {
+		v, err := p.parseValue(s)
+		if err != nil {
+			return err
+		}
+		values = append(values, v)
 	}
+	p.value = values
 	p.isSet = true
 	return nil
 }
 
+// typeError returns a ParseError for values with the wrong type
+func (p *parameter) typeError(value interface{}, format string, args ...interface{}) error {
+	return &ParseError{Name: p.name, Value: fmt.Sprint(value), msg: fmt.Sprintf(format, args...)}
+}
+
 // SetValue sets the parameter value
 func (p *parameter) SetValue(value interface{}) error {
-	if toInternalType(value) != p.paramtype {
-		return fmt.Errorf("can't set %s to %v since type is %T", p.name, value, value)
+	if p.isMap {
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return p.typeError(value, "can't set %s to %v since it is a map", p.name, value)
+		}
+		for _, v := range values {
+			if !p.validType(v) {
+				return p.typeError(v, "can't set %s to %v since type is %T", p.name, v, v)
+			}
+		}
+		p.value = values
+		p.isSet = true
+		return nil
 	}
-	switch p.paramtype {
-	case stringType:
-		p.value = value.(string)
-	case uintType:
-		p.value = value.(uint)
-	case intType:
-		p.value = value.(int)
-	case floatType:
-		p.value = value.(float64)
-	case boolType:
-		p.value = value.(bool)
-	case durationType:
-		v := value.(time.Duration)
-		p.value = v
-	default:
-		return fmt.Errorf("unknown type: %v", p.paramtype)
+	if p.slice {
+		values, ok := value.([]interface{})
+		if !ok {
+			return p.typeError(value, "can't set %s to %v since it is a list", p.name, value)
+		}
+		for _, v := range values {
+			if !p.validType(v) {
+				return p.typeError(v, "can't set %s to %v since type is %T", p.name, v, v)
+			}
+		}
+		p.value = values
+		p.isSet = true
+		return nil
+	}
+	if !p.validType(value) {
+		return p.typeError(value, "can't set %s to %v since type is %T", p.name, value, value)
 	}
+	p.value = value
 	p.isSet = true
 	return nil
 }
@@ -192,19 +439,43 @@ func newParameter(prefix string, field reflect.StructField, value interface{}, f
 	}
 	ret.name = prefix + field.Name
 	attribs := strings.Split(tagValue, ";")
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Ptr {
+		// Pointers are left as nil if the parameter isn't set
+		ret.pointer = true
+		fieldType = fieldType.Elem()
+		value = reflect.Zero(fieldType).Interface()
+	}
+	// Types like net.IP are slices but they are handled as a single value
+	builtin := toInternalType(value) != invalidType
+	if !builtin && fieldType.Kind() == reflect.Slice {
+		// Slices use the element type for parsing and validation
+		ret.slice = true
+		value = reflect.Zero(fieldType.Elem()).Interface()
+	}
+	if !builtin && fieldType.Kind() == reflect.Map {
+		// Maps use the value type for parsing and validation. Keys are strings.
+		if fieldType.Key().Kind() != reflect.String {
+			return nil, tagError(ret.name, "", "field %s must have string keys", ret.name)
+		}
+		ret.isMap = true
+		value = reflect.Zero(fieldType.Elem()).Interface()
+	}
 	ret.paramtype = toInternalType(value)
+	ret.fieldType = reflect.TypeOf(value)
 	if ret.paramtype == invalidType {
-		return nil, fmt.Errorf("field %s has an unknown field type", ret.name)
+		return nil, tagError(ret.name, "", "field %s has an unknown field type", ret.name)
 	}
 	ret.value = nil
 	for _, v := range attribs {
-		tv := strings.Split(v, "=")
-		if len(tv) > 2 {
-			return nil, fmt.Errorf("invalid format for parameter %s: %s", ret.name, v)
-		}
+		tv := strings.SplitN(v, "=", 2)
 		if tv[0] == "" {
 			continue
 		}
+		if len(tv) == 1 {
+			// Keywords without values
+			tv = append(tv, "")
+		}
 		switch strings.ToLower(strings.TrimSpace(tv[0])) {
 		case "desc":
 			ret.description = tv[1]
@@ -212,106 +483,273 @@ func newParameter(prefix string, field reflect.StructField, value interface{}, f
 			ret.defaultValue = tv[1]
 		case "min":
 			ret.minvalue = tv[1]
-			if ret.paramtype != intType &&
-				ret.paramtype != uintType &&
-				ret.paramtype != floatType {
-				return nil, fmt.Errorf("field %s must be a numeric type if min parameter is set", ret.name)
+			if !ret.paramtype.isOrdered() {
+				return nil, tagError(ret.name, "min", "field %s must be a numeric type if min parameter is set", ret.name)
 			}
 		case "max":
 			ret.maxvalue = tv[1]
-			if ret.paramtype != intType &&
-				ret.paramtype != uintType &&
-				ret.paramtype != floatType {
-				return nil, fmt.Errorf("field %s must be a numeric type if max parameter is set", ret.name)
+			if !ret.paramtype.isOrdered() {
+				return nil, tagError(ret.name, "max", "field %s must be a numeric type if max parameter is set", ret.name)
 			}
 		case "file":
 			if ret.paramtype != stringType {
-				return nil, fmt.Errorf("field %s must be of string type if file flag is set", ret.name)
+				return nil, tagError(ret.name, "file", "field %s must be of string type if file flag is set", ret.name)
 			}
 			ret.file = true
 
+		case "layout":
+			if ret.paramtype != timeType {
+				return nil, tagError(ret.name, "layout", "field %s must be a time.Time if layout parameter is set", ret.name)
+			}
+			ret.layout = tv[1]
 		case "required":
 			ret.required = true
+		case "short":
+			if len(tv[1]) != 1 || !isFlagChar(tv[1][0]) {
+				return nil, tagError(ret.name, "short", "short flag for field %s must be a single letter or digit", ret.name)
+			}
+			ret.short = tv[1]
+		case "alias":
+			for _, alias := range strings.Split(tv[1], ",") {
+				alias = strings.TrimSpace(alias)
+				if len(alias) < 2 || strings.HasPrefix(alias, "-") || strings.ContainsAny(alias, "= ") {
+					return nil, tagError(ret.name, "alias", "invalid alias for field %s: %q", ret.name, alias)
+				}
+				ret.aliases = append(ret.aliases, alias)
+			}
+		case "arg":
+			n, err := strconv.Atoi(strings.TrimSpace(tv[1]))
+			if err != nil || n < 0 {
+				return nil, tagError(ret.name, "arg", "invalid argument position for field %s", ret.name)
+			}
+			if ret.isMap {
+				return nil, tagError(ret.name, "arg", "field %s can't be a map if arg parameter is set", ret.name)
+			}
+			ret.positional = true
+			ret.position = n
+		case "minlen":
+			if !ret.slice && !ret.isMap {
+				return nil, tagError(ret.name, "minlen", "field %s must be a slice or map if minlen parameter is set", ret.name)
+			}
+			ret.minlen = tv[1]
+		case "maxlen":
+			if !ret.slice && !ret.isMap {
+				return nil, tagError(ret.name, "maxlen", "field %s must be a slice or map if maxlen parameter is set", ret.name)
+			}
+			ret.maxlen = tv[1]
 		case "options":
 			if ret.paramtype != stringType {
-				return nil, fmt.Errorf("field %s must be of string type if options flag is set", ret.name)
+				return nil, tagError(ret.name, "options", "field %s must be of string type if options flag is set", ret.name)
 			}
 			ret.options = strings.Split(tv[1], ",")
 			if len(ret.options) == 1 && ret.options[0] == "" {
-				return nil, fmt.Errorf("field %s does not contain any options", ret.name)
+				return nil, tagError(ret.name, "options", "field %s does not contain any options", ret.name)
 			}
 		default:
-			return nil, fmt.Errorf("field %s has invalid tags", ret.name)
+			return nil, tagError(ret.name, tv[0], "field %s has invalid tags", ret.name)
 		}
 	}
 	if ret.minvalue != "" {
-		// ensure value is legal
-		if _, err := strconv.ParseFloat(ret.minvalue, 64); err != nil {
-			return nil, fmt.Errorf("invalid min value for field %s", ret.name)
+		// ensure value is legal. The bounds use the same type as the field.
+		if _, err := ret.parseValue(ret.minvalue); err != nil {
+			return nil, tagError(ret.name, "min", "invalid min value for field %s", ret.name)
 		}
 	}
 	if ret.maxvalue != "" {
-		if _, err := strconv.ParseFloat(ret.maxvalue, 64); err != nil {
-			return nil, fmt.Errorf("invalid max value for field %s", ret.name)
+		if _, err := ret.parseValue(ret.maxvalue); err != nil {
+			return nil, tagError(ret.name, "max", "invalid max value for field %s", ret.name)
+		}
+	}
+	if ret.minlen != "" {
+		if n, err := strconv.Atoi(ret.minlen); err != nil || n < 0 {
+			return nil, tagError(ret.name, "minlen", "invalid minlen value for field %s", ret.name)
+		}
+	}
+	if ret.maxlen != "" {
+		if n, err := strconv.Atoi(ret.maxlen); err != nil || n < 0 {
+			return nil, tagError(ret.name, "maxlen", "invalid maxlen value for field %s", ret.name)
 		}
 	}
 	if ret.defaultValue != "" {
 		if err := ret.SetValueAsString(ret.defaultValue); err != nil {
-			return nil, err
+			return nil, tagError(ret.name, "default", "invalid default value for field %s: %s", ret.name, ret.defaultValue)
 		}
+		ret.source = SourceDefault
 	}
 	ret.isSet = false
 	return &ret, nil
 }
 
-func (p *parameter) toFloat() float64 {
-	switch p.paramtype {
-	case intType:
-		return float64(p.value.(int))
-	case uintType:
-		return float64(p.value.(uint))
-	case floatType:
-		return p.value.(float64)
+// compareValues compares a value with a bound of the same type. It returns
+// -1, 0 or 1 if the value is less than, equal to or greater than the bound.
+func compareValues(value, bound interface{}) int {
+	switch b := bound.(type) {
+	case int64:
+		v := value.(int64)
+		if v < b {
+			return -1
+		}
+		if v > b {
+			return 1
+		}
+	case uint64:
+		v := value.(uint64)
+		if v < b {
+			return -1
+		}
+		if v > b {
+			return 1
+		}
+	case float64:
+		v := value.(float64)
+		if v < b {
+			return -1
+		}
+		if v > b {
+			return 1
+		}
+	case ByteSize:
+		v := value.(ByteSize)
+		if v < b {
+			return -1
+		}
+		if v > b {
+			return 1
+		}
+	case time.Duration:
+		v := value.(time.Duration)
+		if v < b {
+			return -1
+		}
+		if v > b {
+			return 1
+		}
+	case time.Time:
+		v := value.(time.Time)
+		if v.Before(b) {
+			return -1
+		}
+		if v.After(b) {
+			return 1
+		}
 	}
 	return 0
 }
 
-func (p *parameter) validate() error {
+// validationError creates a validation error for the parameter
+func (p *parameter) validationError(rule string, value interface{}, err error) *ValidationError {
+	ret := &ValidationError{
+		Name: p.name,
+		Flag: p.usageName(),
+		Env:  p.envName(),
+		Rule: rule,
+		Err:  err,
+	}
+	if value != nil {
+		ret.Value = formatValue(value)
+		ret.Source = p.source
+	}
+	return ret
+}
+
+// validate validates the parameter and returns all of the errors. Lists and
+// maps are validated per element.
+func (p *parameter) validate() ValidationErrors {
 	if p.required && !p.isSet {
-		return fmt.Errorf("missing required parameter: %s", p.name)
+		return ValidationErrors{p.validationError(RuleRequired, nil, fmt.Errorf("missing required parameter: %s", p.name))}
 	}
-	if p.minvalue != "" {
-		v, _ := strconv.ParseFloat(p.minvalue, 64)
+	if p.pointer && !p.isSet && p.value == nil {
+		// Unset pointer fields are nil and aren't validated
+		return nil
+	}
+	if !p.slice && !p.isMap {
+		if err := p.validateValue(p.value); err != nil {
+			return ValidationErrors{err}
+		}
+		return nil
+	}
+	var values []interface{}
+	var list []string
+	switch v := p.value.(type) {
+	case []interface{}:
+		values = v
+		for i := range v {
+			list = append(list, formatValue(v[i]))
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			values = append(values, v[k])
+			list = append(list, k+"="+formatValue(v[k]))
+		}
+	}
+	var errs ValidationErrors
+	if p.minlen != "" {
+		n, _ := strconv.Atoi(p.minlen)
+		if len(values) < n {
+			errs = append(errs, p.validationError(RuleMinLen, strings.Join(list, ","), fmt.Errorf("%s needs at least %d values", p.name, n)))
+		}
+	}
+	if p.maxlen != "" {
+		n, _ := strconv.Atoi(p.maxlen)
+		if len(values) > n {
+			errs = append(errs, p.validationError(RuleMaxLen, strings.Join(list, ","), fmt.Errorf("%s can't have more than %d values", p.name, n)))
+		}
+	}
+	for _, v := range values {
+		if err := p.validateValue(v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
 
-		if p.toFloat() < v {
-			return fmt.Errorf("value for %s is below the minimum", p.name)
+// validateValue validates a single value. Lists are validated per element.
+func (p *parameter) validateValue(value interface{}) *ValidationError {
+	original := value
+	if value == nil && p.paramtype.isOrdered() {
+		// Unset values are compared as zero
+		zero, _ := p.parseValue("0")
+		if p.paramtype == timeType {
+			zero = time.Time{}
+		}
+		value = zero
+	}
+	if p.minvalue != "" {
+		v, _ := p.parseValue(p.minvalue)
+		if compareValues(value, v) < 0 {
+			return p.validationError(RuleMin, original, fmt.Errorf("value %s for %s is below the minimum %s", formatValue(value), p.name, formatValue(v)))
 		}
 	}
 	if p.maxvalue != "" {
-		v, _ := strconv.ParseFloat(p.maxvalue, 64)
-		if p.toFloat() > v {
-			return fmt.Errorf("value for %s is above the minimum", p.name)
+		v, _ := p.parseValue(p.maxvalue)
+		if compareValues(value, v) > 0 {
+			return p.validationError(RuleMax, original, fmt.Errorf("value %s for %s is above the maximum %s", formatValue(value), p.name, formatValue(v)))
 		}
 	}
 	if len(p.options) > 0 {
 		found := false
 		for _, v := range p.options {
-			if p.value == nil {
+			if value == nil {
 				continue
 			}
-			if strings.EqualFold(v, p.value.(string)) {
+			if strings.EqualFold(v, value.(string)) {
 				found = true
 				break
 			}
 		}
 		if !found {
-			return fmt.Errorf("option %v is not valid for %s", p.value, p.name)
+			return p.validationError(RuleOptions, value, fmt.Errorf("option %v is not valid for %s", value, p.name))
 		}
 	}
 
-	if p.file && p.value != nil && p.value.(string) != "" {
-		if _, err := os.Stat(p.value.(string)); err != nil {
-			return err
+	if p.file && value != nil && value.(string) != "" {
+		if _, err := os.Stat(value.(string)); err != nil {
+			return p.validationError(RuleFile, value, err)
 		}
 	}
 	return nil
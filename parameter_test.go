@@ -16,9 +16,13 @@ package params
 //limitations under the License.
 //
 import (
+	"net"
+	"net/url"
 	"os"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestHyphenName(t *testing.T) {
@@ -175,3 +179,198 @@ func TestFileType(t *testing.T) {
 		t.Fatal("Expected no error when file exists: ", err)
 	}
 }
+
+func TestInvalidListLength(t *testing.T) {
+	var pc1 struct {
+		Val string `param:"desc=foo;minlen=1"`
+	}
+	if err := NewEnv(&pc1); err == nil {
+		t.Fatal("Expected error when minlen is set for non-slice")
+	}
+	var pc2 struct {
+		Val []string `param:"desc=foo;maxlen=x"`
+	}
+	if err := NewEnv(&pc2); err == nil {
+		t.Fatal("Expected error when maxlen is invalid")
+	}
+}
+
+func TestInvalidMapKey(t *testing.T) {
+	var pc1 struct {
+		Val map[int]string `param:"desc=foo"`
+	}
+	if err := NewEnv(&pc1); err == nil {
+		t.Fatal("Expected error when map key isn't a string")
+	}
+}
+
+func TestSizedNumbers(t *testing.T) {
+	var cfg struct {
+		Small      int8    `param:"desc=int8"`
+		Big        int64   `param:"desc=int64"`
+		QueueDepth uint8   `param:"desc=uint8;default=10"`
+		Port       uint16  `param:"desc=uint16"`
+		Huge       uint64  `param:"desc=uint64"`
+		Ratio      float32 `param:"desc=float32"`
+	}
+	if err := NewFlag(&cfg, []string{
+		"--small", "-128",
+		"--big", "9223372036854775807",
+		"--port", "65535",
+		"--huge", "18446744073709551615",
+		"--ratio", "0.5",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Small != -128 || cfg.Big != 9223372036854775807 || cfg.QueueDepth != 10 ||
+		cfg.Port != 65535 || cfg.Huge != 18446744073709551615 || cfg.Ratio != 0.5 {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+
+	err := NewEnv(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("QUEUE_DEPTH", "300")
+	defer os.Unsetenv("QUEUE_DEPTH")
+	err = NewEnv(&cfg)
+	if err == nil || err.Error() != "value 300 overflows uint8 for --queue-depth" {
+		t.Fatalf("Expected overflow error but got %v", err)
+	}
+
+	if err := NewFile(&cfg, strings.NewReader(`{"ratio": 1e300}`)); err == nil {
+		t.Fatal("Expected error when float32 overflows")
+	}
+	if err := NewFile(&cfg, strings.NewReader(`{"small": 128}`)); err == nil {
+		t.Fatal("Expected error when int8 overflows")
+	}
+}
+
+func TestLargeMinMax(t *testing.T) {
+	var cfg struct {
+		Val  int64  `param:"desc=foo;min=9007199254740993"`
+		UVal uint64 `param:"desc=foo;max=18446744073709551614"`
+		Val8 int8   `param:"desc=foo;max=200"`
+	}
+	if _, err := newConfigParameters(&cfg); err == nil {
+		t.Fatal("Expected error when max overflows the field")
+	}
+	var cfg2 struct {
+		Val  int64  `param:"desc=foo;min=9007199254740993"`
+		UVal uint64 `param:"desc=foo;max=18446744073709551614"`
+	}
+	// 9007199254740992 is equal to the minimum when converted to float64
+	if err := NewFlag(&cfg2, []string{"--val", "9007199254740992"}); err == nil {
+		t.Fatal("Expected error when value is below the minimum")
+	}
+	if err := NewFlag(&cfg2, []string{"--val", "9007199254740993", "--u-val", "18446744073709551615"}); err == nil {
+		t.Fatal("Expected error when value is above the maximum")
+	}
+	if err := NewFlag(&cfg2, []string{"--val", "9007199254740993", "--u-val", "18446744073709551614"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuiltinTypes(t *testing.T) {
+	var cfg struct {
+		Cutoff   time.Time      `param:"desc=Cutoff"`
+		Day      time.Time      `param:"desc=Day;layout=2006-01-02;default=2019-05-17"`
+		Endpoint *url.URL       `param:"desc=Endpoint"`
+		Upstream url.URL        `param:"desc=Upstream;default=http://localhost/"`
+		BindIP   net.IP         `param:"desc=Bind address;default=127.0.0.1"`
+		Allow    []net.IPNet    `param:"desc=Allowed networks"`
+		Match    *regexp.Regexp `param:"desc=Match"`
+	}
+	if err := NewFlag(&cfg, []string{
+		"--cutoff", "2019-01-02T03:04:05Z",
+		"--endpoint", "https://example.com/api",
+		"--allow", "10.0.0.0/8,192.168.0.0/16",
+		"--match", "^a+$",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Cutoff.Year() != 2019 || cfg.Cutoff.Hour() != 3 {
+		t.Fatalf("Time isn't set: %+v", cfg.Cutoff)
+	}
+	if cfg.Day.Day() != 17 {
+		t.Fatalf("Time with layout isn't set: %+v", cfg.Day)
+	}
+	if cfg.Endpoint == nil || cfg.Endpoint.Host != "example.com" || cfg.Upstream.Host != "localhost" {
+		t.Fatalf("URLs aren't set: %+v %+v", cfg.Endpoint, cfg.Upstream)
+	}
+	if !cfg.BindIP.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Fatalf("IP isn't set: %v", cfg.BindIP)
+	}
+	if len(cfg.Allow) != 2 || !cfg.Allow[1].Contains(net.IPv4(192, 168, 1, 1)) {
+		t.Fatalf("Networks aren't set: %v", cfg.Allow)
+	}
+	if cfg.Match == nil || !cfg.Match.MatchString("aaa") {
+		t.Fatalf("Regexp isn't set: %v", cfg.Match)
+	}
+
+	os.Setenv("BIND_IP", "::1")
+	defer os.Unsetenv("BIND_IP")
+	if err := NewEnv(&cfg); err != nil || !cfg.BindIP.Equal(net.IPv6loopback) {
+		t.Fatalf("IP isn't set from environment: %v %v", err, cfg.BindIP)
+	}
+	if err := NewFile(&cfg, strings.NewReader(`{"allow": ["10.0.0.0/24"], "match": "b"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Allow) != 1 || !cfg.Match.MatchString("abc") {
+		t.Fatalf("Values aren't set from file: %+v", cfg)
+	}
+
+	os.Setenv("BIND_IP", "300.0.0.1")
+	err := NewEnv(&cfg)
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid value for field BindIP") {
+		t.Fatalf("Expected invalid value error but got %v", err)
+	}
+	os.Unsetenv("BIND_IP")
+	if err := NewFile(&cfg, strings.NewReader(`{"match": "(a"}`)); err == nil {
+		t.Fatal("Expected error with invalid regexp")
+	}
+	if err := NewFile(&cfg, strings.NewReader(`{"day": "2019-05-17T00:00:00Z"}`)); err == nil {
+		t.Fatal("Expected error when time doesn't match the layout")
+	}
+	var invalid struct {
+		Val string `param:"desc=foo;layout=2006"`
+	}
+	if err := NewEnv(&invalid); err == nil {
+		t.Fatal("Expected error when layout is used on a string")
+	}
+}
+
+func TestDurationMinMax(t *testing.T) {
+	var cfg struct {
+		Timeout  time.Duration   `param:"desc=Timeout;min=100ms;max=30s;default=1s"`
+		Backoff  []time.Duration `param:"desc=Backoff;max=1m"`
+		NotAfter time.Time       `param:"desc=Not after;layout=2006-01-02;min=2019-01-01;max=2020-01-01"`
+	}
+	if err := NewFlag(&cfg, []string{"--not-after", "2019-06-01"}); err != nil {
+		t.Fatal(err)
+	}
+	err := NewFlag(&cfg, []string{"--timeout", "50ms", "--not-after", "2019-06-01"})
+	if err == nil || err.Error() != "value 50ms for Timeout is below the minimum 100ms" {
+		t.Fatalf("Expected minimum error but got %v", err)
+	}
+	err = NewFlag(&cfg, []string{"--timeout", "1m", "--not-after", "2019-06-01"})
+	if err == nil || err.Error() != "value 1m0s for Timeout is above the maximum 30s" {
+		t.Fatalf("Expected maximum error but got %v", err)
+	}
+	if err := NewFlag(&cfg, []string{"--backoff", "1s,2m", "--not-after", "2019-06-01"}); err == nil {
+		t.Fatal("Expected error when list element is above max")
+	}
+	if err := NewFlag(&cfg, []string{"--not-after", "2021-01-01"}); err == nil {
+		t.Fatal("Expected error when time is above max")
+	}
+	if err := NewFlag(&cfg, []string{}); err == nil {
+		t.Fatal("Expected error when unset time is below min")
+	}
+
+	var invalid struct {
+		Timeout time.Duration `param:"desc=Timeout;min=100"`
+	}
+	if err := NewEnv(&invalid); err == nil {
+		t.Fatal("Expected error when min isn't a duration")
+	}
+}
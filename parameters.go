@@ -16,8 +16,6 @@ package params
 //limitations under the License.
 //
 import (
-	"errors"
-	"fmt"
 	"reflect"
 	"strings"
 	"time"
@@ -27,30 +25,36 @@ import (
 // configParameters is the internal (flattened) representation of the
 // configuration parameters.
 type configParameters struct {
-	params []parameter
+	params   []parameter
+	commands []command
+	command  *Command
 }
 
 // newConfigParameters creates a configuration parameter set based on
 // the supplied pointer to a configuration struct
 func newConfigParameters(config interface{}) (*configParameters, error) {
 	if config == nil {
-		return nil, errors.New("config must be non-nil")
+		return nil, ErrNilConfig
 	}
 	if reflect.TypeOf(config).Kind() != reflect.Ptr {
-		return nil, errors.New("needs pointer to configuration")
+		return nil, ErrNotPointer
 	}
 	ret := configParameters{
 		params: make([]parameter, 0),
 	}
-	var err error
-	if ret.params, err = readParameters("", config, ret.params); err != nil {
+	if err := ret.readParameters("", nil, config); err != nil {
+		return nil, err
+	}
+	if err := ret.checkArguments(); err != nil {
 		return nil, err
 	}
 
 	return &ret, nil
 }
 
-func readParameters(prefix string, value interface{}, params []parameter) ([]parameter, error) {
+// readParameters reads the parameters in a struct. The command is the
+// command the struct belongs to. It is nil for the global parameters.
+func (c *configParameters) readParameters(prefix string, cmd *command, value interface{}) error {
 	ct := reflect.TypeOf(value)
 	vt := reflect.ValueOf(value)
 	if ct.Kind() == reflect.Ptr {
@@ -58,38 +62,86 @@ func readParameters(prefix string, value interface{}, params []parameter) ([]par
 		vt = vt.Elem()
 	}
 	if ct.Kind() != reflect.Struct {
-		return nil, errors.New("needs struct type for configuration")
+		return ErrNotStruct
 	}
 	for i := 0; i < ct.NumField(); i++ {
 		field := ct.Field(i)
 		// Skip private fields
 		if unicode.IsLower(rune(field.Name[0])) {
 			if _, ok := field.Tag.Lookup(tagName); ok {
-				return nil, fmt.Errorf("field %s is unexported but has tag", field.Name)
+				return tagError(prefix+field.Name, "", "field %s is unexported but has tag", field.Name)
 			}
 			continue
 		}
 		if !vt.Field(i).CanInterface() {
-			return nil, fmt.Errorf("cannot set field %s", field.Name)
+			return tagError(prefix+field.Name, "", "cannot set field %s", field.Name)
+		}
+		// Commands are structs with the cmd keyword in the tag
+		sub, err := newCommand(prefix, cmd, field)
+		if err != nil {
+			return err
+		}
+		if sub != nil {
+			c.commands = append(c.commands, *sub)
+			if err := c.readParameters(sub.prefix, sub, reflect.New(structType(field.Type)).Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+		// Structs are nested parameters unless they are custom types
+		if vt.Field(i).Kind() == reflect.Struct && toInternalType(vt.Field(i).Interface()) == invalidType {
+			if err := c.readParameters(prefix+field.Name+".", cmd, vt.Field(i).Interface()); err != nil {
+				return err
+			}
+			continue
 		}
-		if vt.Field(i).Kind() == reflect.Struct {
-			var err error
-			params, err = readParameters(prefix+field.Name+".", vt.Field(i).Interface(), params)
-			if err != nil {
-				return nil, err
+		// Pointers to structs are nested parameters as well. The struct is
+		// allocated when one of its parameters is set.
+		if isNestedPointer(field.Type) {
+			if err := c.readParameters(prefix+field.Name+".", cmd, reflect.New(field.Type.Elem()).Interface()); err != nil {
+				return err
 			}
 			continue
 		}
 		param, err := newParameter(prefix, field, vt.Field(i).Interface(), vt.Field(i))
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if param == nil {
 			continue
 		}
-		params = append(params, *param)
+		if cmd != nil {
+			param.command = cmd.name
+			param.cmdPrefix = cmd.prefix
+		}
+		c.params = append(c.params, *param)
+	}
+	return nil
+}
+
+// isNestedPointer returns true if the type is a pointer to a nested
+// configuration struct
+func isNestedPointer(t reflect.Type) bool {
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	return toInternalType(reflect.Zero(t.Elem()).Interface()) == invalidType
+}
+
+// fieldByName returns the struct field for a parameter name. Nil pointers to
+// nested structs are allocated on the way.
+func fieldByName(config interface{}, name string) reflect.Value {
+	f := reflect.ValueOf(config).Elem()
+	for _, n := range strings.Split(name, ".") {
+		if f.Kind() == reflect.Ptr {
+			if f.IsNil() {
+				f.Set(reflect.New(f.Type().Elem()))
+			}
+			f = f.Elem()
+		}
+		f = f.FieldByName(n)
 	}
-	return params, nil
+	return f
 }
 
 func (c *configParameters) getParameter(name string) *parameter {
@@ -101,43 +153,138 @@ func (c *configParameters) getParameter(name string) *parameter {
 	return nil
 }
 
-// AssignValues assigns the current parameter config to the struct.
+// AssignValues assigns the current parameter config to the struct. Parameters
+// without a value are left untouched so pointer fields stay nil unless the
+// parameter is set or has a default.
 func (c *configParameters) AssignValues(config interface{}) error {
 	for _, v := range c.params {
-		fields := strings.Split(v.name, ".")
-		f := reflect.ValueOf(config).Elem().FieldByName(fields[0])
-		for n := 1; n < len(fields); n++ {
-			f = f.FieldByName(fields[n])
+		if v.value == nil {
+			continue
 		}
-		if toInternalType(f.Interface()) != v.paramtype {
-			return fmt.Errorf("invalid type for %s: %v (%T)", v.name, v.value, v.value)
+		f := fieldByName(config, v.name)
+		if v.pointer {
+			if f.Kind() != reflect.Ptr {
+				return tagError(v.name, "", "invalid type for %s: %v (%T)", v.name, v.value, v.value)
+			}
+			if f.IsNil() {
+				f.Set(reflect.New(f.Type().Elem()))
+			}
+			f = f.Elem()
 		}
-		if v.value == nil {
+		if v.isMap {
+			if f.Kind() != reflect.Map || toInternalType(reflect.Zero(f.Type().Elem()).Interface()) != v.paramtype {
+				return tagError(v.name, "", "invalid type for %s: %v (%T)", v.name, v.value, v.value)
+			}
+			values := v.value.(map[string]interface{})
+			m := reflect.MakeMapWithSize(f.Type(), len(values))
+			for k := range values {
+				elem := reflect.New(f.Type().Elem()).Elem()
+				assignValue(elem, v.paramtype, values[k])
+				m.SetMapIndex(reflect.ValueOf(k).Convert(f.Type().Key()), elem)
+			}
+			f.Set(m)
 			continue
 		}
-		switch v.paramtype {
-		case stringType:
-			f.SetString(v.value.(string))
-		case intType:
-			f.SetInt(int64(v.value.(int)))
-		case uintType:
-			f.SetUint(uint64(v.value.(uint)))
-		case boolType:
-			f.SetBool(v.value.(bool))
-		case durationType:
-			f.SetInt(int64(v.value.(time.Duration)))
-		case floatType:
-			f.SetFloat(v.value.(float64))
+		if v.slice {
+			if f.Kind() != reflect.Slice || toInternalType(reflect.Zero(f.Type().Elem()).Interface()) != v.paramtype {
+				return tagError(v.name, "", "invalid type for %s: %v (%T)", v.name, v.value, v.value)
+			}
+			values := v.value.([]interface{})
+			list := reflect.MakeSlice(f.Type(), len(values), len(values))
+			for i := range values {
+				assignValue(list.Index(i), v.paramtype, values[i])
+			}
+			f.Set(list)
+			continue
+		}
+		if toInternalType(f.Interface()) != v.paramtype {
+			return tagError(v.name, "", "invalid type for %s: %v (%T)", v.name, v.value, v.value)
 		}
+		assignValue(f, v.paramtype, v.value)
 	}
 	return nil
 }
 
+// assignValue sets a single struct field (or slice or map element) to the value
+func assignValue(f reflect.Value, paramtype internalType, value interface{}) {
+	switch paramtype {
+	case stringType:
+		f.SetString(value.(string))
+	case intType:
+		f.SetInt(value.(int64))
+	case uintType:
+		f.SetUint(value.(uint64))
+	case boolType:
+		f.SetBool(value.(bool))
+	case durationType:
+		f.SetInt(int64(value.(time.Duration)))
+	case floatType:
+		f.SetFloat(value.(float64))
+	case timeType, urlType, ipType, ipNetType, regexpType, byteSizeType, customType, textType:
+		f.Set(reflect.ValueOf(value))
+	}
+}
+
+// Validate validates all of the parameters. The returned error is a
+// ValidationErrors list with every parameter that failed. Parameters for
+// commands that aren't selected are skipped.
 func (c *configParameters) Validate() error {
+	var errs ValidationErrors
 	for _, v := range c.params {
-		if err := v.validate(); err != nil {
-			return err
+		if !c.isActive(&v, c.selectedCommand()) {
+			continue
 		}
+		errs = append(errs, v.validate()...)
+	}
+	if len(errs) > 0 {
+		return errs
 	}
 	return nil
 }
+
+// closestName returns the name of the parameter that is closest to the name
+// or an empty string if none of the parameters are close.
+func (c *configParameters) closestName(name string) string {
+	name = strings.ToLower(name)
+	best := ""
+	bestDistance := len(name)/3 + 1
+	for i := range c.params {
+		d := editDistance(name, strings.ToLower(c.params[i].name))
+		if d < bestDistance {
+			best = c.params[i].name
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -16,6 +16,7 @@ package params
 //limitations under the License.
 //
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -96,3 +97,58 @@ func TestNonStruct(t *testing.T) {
 		t.Fatal("Expected error")
 	}
 }
+
+func TestPointerFields(t *testing.T) {
+	type tlsConfig struct {
+		CertFile string `param:"desc=Cert file"`
+		Port     int    `param:"desc=Port"`
+	}
+	var cfg struct {
+		Retries *int           `param:"desc=Retries"`
+		Name    *string        `param:"desc=Name;default=foo"`
+		Timeout *time.Duration `param:"desc=Timeout"`
+		Verbose *bool          `param:"desc=Verbose"`
+		TLS     *tlsConfig
+		Other   *tlsConfig
+	}
+	if err := NewFlag(&cfg, []string{"--retries", "0", "--tls-port", "443"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Retries == nil || *cfg.Retries != 0 {
+		t.Fatalf("Retries should be set to 0: %+v", cfg)
+	}
+	if cfg.Name == nil || *cfg.Name != "foo" {
+		t.Fatalf("Name should be set to the default: %+v", cfg)
+	}
+	if cfg.Timeout != nil || cfg.Verbose != nil {
+		t.Fatalf("Unset pointers should be nil: %+v", cfg)
+	}
+	if cfg.TLS == nil || cfg.TLS.Port != 443 {
+		t.Fatalf("Nested struct should be allocated: %+v", cfg)
+	}
+	if cfg.Other != nil {
+		t.Fatalf("Nested struct without values should be nil: %+v", cfg)
+	}
+
+	var optional struct {
+		Retries *int       `param:"desc=Retries;min=1"`
+		Since   *time.Time `param:"desc=Since;min=2020-01-01T00:00:00Z"`
+		Mode    *string    `param:"desc=Mode;options=a,b"`
+		Cert    *string    `param:"desc=Cert;file"`
+	}
+	if err := NewFlag(&optional, []string{}); err != nil {
+		t.Fatalf("Unset pointers shouldn't be validated: %v", err)
+	}
+	if optional.Retries != nil || optional.Since != nil || optional.Mode != nil || optional.Cert != nil {
+		t.Fatalf("Unset pointers should be nil: %+v", optional)
+	}
+	if err := NewFlag(&optional, []string{"--retries", "0"}); !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("Expected range error for a set pointer but got %v", err)
+	}
+	var required struct {
+		Retries *int `param:"desc=Retries;min=1;required"`
+	}
+	if err := NewFlag(&required, []string{}); !errors.Is(err, ErrRequired) {
+		t.Fatalf("Expected required error but got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NewPropertiesFile populates a config struct with values from a Java
+// .properties file. Dotted keys are nested structs and the keys use the same
+// naming as JSON files (see NewFile):
+//
+//  logType = plain
+//  http.endpoint = localhost:1234
+//  labels.team = backend
+//
+// Lists are comma-separated and map parameters can be set with dotted keys
+// or with a single comma-separated list of key=value pairs.
+func NewPropertiesFile(config interface{}, reader io.Reader, opts ...FileOption) error {
+	return NewLoader(PropertiesSource(reader, opts...)).Load(config)
+}
+
+// propertiesSource reads values from a .properties file
+type propertiesSource struct {
+	reader  io.Reader
+	options fileOptions
+}
+
+// PropertiesSource returns a source that reads the parameters from a
+// .properties file. See NewPropertiesFile for the format.
+func PropertiesSource(reader io.Reader, opts ...FileOption) Source {
+	return &propertiesSource{reader: reader, options: newFileOptions(opts)}
+}
+
+func (p *propertiesSource) Name() string {
+	return SourceFile
+}
+
+func (p *propertiesSource) Load(set *ParameterSet) error {
+	if p.reader == nil {
+		return ErrNilReader
+	}
+	entries, err := parseProperties(p.reader)
+	if err != nil {
+		return err
+	}
+	unknown, err := setEntries(set, entries)
+	if err != nil {
+		return err
+	}
+	return p.options.checkUnknown(set.params, unknown)
+}
+
+// parseProperties reads the entries in a .properties file. Comments start
+// with # or !, lines ending with a backslash continue on the next line and
+// the key is separated from the value with =, : or white space.
+func parseProperties(reader io.Reader) ([]fileEntry, error) {
+	var entries []fileEntry
+	scanner := bufio.NewScanner(reader)
+	logical := ""
+	start := 0
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical == "" {
+			if text == "" || text[0] == '#' || text[0] == '!' {
+				continue
+			}
+			start = line
+		}
+		if continued(text) {
+			logical += text[:len(text)-1]
+			continue
+		}
+		entry, err := parseProperty(logical + text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", start, err)
+		}
+		entry.line = start
+		entries = append(entries, entry)
+		logical = ""
+	}
+	if logical != "" {
+		entry, err := parseProperty(logical)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", start, err)
+		}
+		entry.line = start
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// continued returns true if the line ends with an odd number of backslashes
+func continued(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// parseProperty splits a single logical line into the key and the value
+func parseProperty(line string) (fileEntry, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	value := strings.TrimLeft(line[end:], " \t\f")
+	if value != "" && (value[0] == '=' || value[0] == ':') {
+		value = strings.TrimLeft(value[1:], " \t\f")
+	}
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return fileEntry{}, err
+	}
+	if key == "" {
+		return fileEntry{}, errors.New("missing key")
+	}
+	if value, err = unescapeProperty(value); err != nil {
+		return fileEntry{}, err
+	}
+	return fileEntry{key: key, value: value}, nil
+}
+
+// unescapeProperty replaces the escape sequences in a key or value
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid escape sequence %q", s[i-1:])
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence %q", s[i-1:i+5])
+			}
+			sb.WriteRune(rune(r))
+			i += 4
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String(), nil
+}
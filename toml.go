@@ -0,0 +1,96 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// NewTOMLFile populates a config struct with values from a TOML file. Tables
+// are nested structs and the keys use the same naming as JSON files (see
+// NewFile):
+//
+//  logType = "plain"
+//  peers = ["a.example.com", "b.example.com"]
+//
+//  [http]
+//  endpoint = "localhost:1234"
+//  timeout = "10s"
+//
+// Integers are read without loss of precision and TOML datetimes can be used
+// for time.Time fields.
+func NewTOMLFile(config interface{}, reader io.Reader, opts ...FileOption) error {
+	return NewLoader(TOMLSource(reader, opts...)).Load(config)
+}
+
+// tomlSource reads values from a TOML file
+type tomlSource struct {
+	reader  io.Reader
+	options fileOptions
+}
+
+// TOMLSource returns a source that reads the parameters from a TOML file.
+// See NewTOMLFile for the format.
+func TOMLSource(reader io.Reader, opts ...FileOption) Source {
+	return &tomlSource{reader: reader, options: newFileOptions(opts)}
+}
+
+func (t *tomlSource) Name() string {
+	return SourceFile
+}
+
+func (t *tomlSource) Load(set *ParameterSet) error {
+	if t.reader == nil {
+		return ErrNilReader
+	}
+	tomlMap := make(map[string]interface{})
+	if _, err := toml.NewDecoder(t.reader).Decode(&tomlMap); err != nil {
+		return err
+	}
+	configMap := make(map[string]interface{})
+	flattenMap("", tomlMap, configMap, set.params)
+	unknown, err := setValues(set, configMap, tomlValue)
+	if err != nil {
+		return err
+	}
+	return t.options.checkUnknown(set.params, unknown)
+}
+
+// tomlValue converts a single value from TOML into the parameter's type.
+// Datetimes are used as is for time.Time fields, everything else is parsed
+// from the string representation.
+func tomlValue(para *parameter, v interface{}) (interface{}, error) {
+	switch tmp := v.(type) {
+	case string:
+		return para.parseValue(tmp)
+	case int64:
+		return para.parseValue(strconv.FormatInt(tmp, 10))
+	case float64:
+		return para.parseValue(strconv.FormatFloat(tmp, 'f', -1, 64))
+	case bool:
+		return para.parseValue(strconv.FormatBool(tmp))
+	case time.Time:
+		if para.paramtype == timeType {
+			return tmp, nil
+		}
+		return para.parseValue(tmp.Format(time.RFC3339Nano))
+	}
+	return nil, para.typeError(v, "can't parse field %s", para.name)
+}
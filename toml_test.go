@@ -0,0 +1,107 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOMLFile(t *testing.T) {
+	config := parameters{}
+
+	if err := NewTOMLFile(&config, nil); err == nil {
+		t.Fatal("Expected error with nil reader")
+	}
+	if err := NewTOMLFile(&config, strings.NewReader("invalid = ")); err == nil {
+		t.Fatal("Expected invalid file format")
+	}
+
+	file := `
+myVal = 12
+myUint = 4711
+myBool = true
+myDuration = "12ms"
+
+[http]
+endpoint = ":1234"
+
+[deviceIO]
+endpoint = "localhost:4711"
+`
+	if err := NewTOMLFile(&config, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if config.HTTP.Endpoint != ":1234" || config.DeviceIO.Endpoint != "localhost:4711" {
+		t.Fatalf("Tables aren't set: %+v", config)
+	}
+	if config.MyVal != 12 || config.MyUint != 4711 || !config.MyBool || config.MyDuration != 12*time.Millisecond {
+		t.Fatalf("Values aren't set: %+v", config)
+	}
+}
+
+func TestTOMLTypes(t *testing.T) {
+	var cfg struct {
+		Big    int64             `param:"desc=Big number"`
+		Small  uint8             `param:"desc=Small number"`
+		Cutoff time.Time         `param:"desc=Cutoff"`
+		Hosts  []string          `param:"desc=Hosts"`
+		Labels map[string]string `param:"desc=Labels"`
+	}
+	file := `
+big = 9007199254740993
+cutoff = 2019-10-01T12:00:00Z
+hosts = ["a", "b"]
+
+[labels]
+key = "value"
+`
+	if err := NewTOMLFile(&cfg, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Big != 9007199254740993 {
+		t.Fatalf("Integer loses precision: %d", cfg.Big)
+	}
+	if !cfg.Cutoff.Equal(time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Datetime isn't set: %v", cfg.Cutoff)
+	}
+	if len(cfg.Hosts) != 2 || cfg.Labels["key"] != "value" {
+		t.Fatalf("Lists and maps aren't set: %+v", cfg)
+	}
+	if err := NewTOMLFile(&cfg, strings.NewReader("small = 300")); err == nil {
+		t.Fatal("Expected overflow error")
+	}
+	if err := NewTOMLFile(&cfg, strings.NewReader("hosts = \"a\"")); err == nil {
+		t.Fatal("Expected error when list isn't a list")
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	name := writeTempFile(t, "loadName = \"toml\"\nloadPort = 8080\n")
+	defer os.Remove(name)
+	os.Rename(name, name+".toml")
+	defer os.Remove(name + ".toml")
+
+	var cfg loadConfig
+	if err := Load(&cfg, []string{"--config", name + ".toml"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LoadName != "toml" || cfg.LoadPort != 8080 {
+		t.Fatalf("Values aren't set from TOML file: %+v", cfg)
+	}
+}
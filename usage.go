@@ -0,0 +1,330 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Usage prints the help text for a program. The parameters are grouped by the
+// nested structs and each parameter lists the flag, the type, the
+// description, the default value, the rules from the tag and the environment
+// variable:
+//
+//  Usage: my-command [options]
+//
+//  Options:
+//    --log-type string
+//          Log type (default: "plain", options: plain|fancy)
+//          Environment: LOG_TYPE
+//
+//  HTTP options:
+//    --http-endpoint string
+//          Server endpoint (required)
+//          Environment: HTTP_ENDPOINT
+//
+// The text is wrapped to the width of the terminal. Set Command to print the
+// help text for a command. The commands below the command are listed with
+// their descriptions. Set ConfigFlag for programs that use Load to list the
+// --config parameter.
+type Usage struct {
+	Program     string // The program name. The name of the executable is used if this is empty
+	Command     string // The command, ie "user add". Empty for the top level help text
+	Description string // Printed before the parameters. The command description is used if this is empty
+	Footer      string // Printed after the parameters
+	Width       int    // The width of the text. The COLUMNS environment variable or 80 is used if this is 0
+	ConfigFlag  bool   // List the --config parameter and the CONFIG_FILE environment variable used by Load
+}
+
+// Print prints the help text for the configuration to the writer
+func (u *Usage) Print(w io.Writer, config interface{}) error {
+	params, err := newConfigParameters(config)
+	if err != nil {
+		return err
+	}
+	u.print(w, params)
+	return nil
+}
+
+// usageGroup is the parameters for a single nested struct
+type usageGroup struct {
+	name   string
+	params []*parameter
+}
+
+func (u *Usage) print(w io.Writer, params *configParameters) {
+	width := u.width()
+	program := u.Program
+	if program == "" {
+		program = filepath.Base(os.Args[0])
+	}
+	if u.Command != "" {
+		program += " " + u.Command
+	}
+	commands := params.subCommands(u.Command)
+	arguments := params.arguments(u.Command)
+	switch {
+	case len(commands) > 0:
+		fmt.Fprintf(w, "Usage: %s [options] <command> [options]\n", program)
+	case len(arguments) > 0:
+		fmt.Fprintf(w, "Usage: %s [options] %s\n", program, argumentList(arguments))
+	default:
+		fmt.Fprintf(w, "Usage: %s [options]\n", program)
+	}
+	description := u.Description
+	if cmd := params.getCommand(u.Command); description == "" && cmd != nil {
+		description = cmd.description
+	}
+	if description != "" {
+		fmt.Fprintf(w, "\n%s", wrapText(description, "", width))
+	}
+	if len(commands) > 0 {
+		fmt.Fprintf(w, "\nCommands:\n")
+		printCommands(w, u.Command, commands, width)
+	}
+	if len(arguments) > 0 {
+		fmt.Fprintf(w, "\nArguments:\n")
+		for _, p := range arguments {
+			printParameter(w, p, width)
+		}
+	}
+	for i, g := range usageGroups(params, u.Command) {
+		switch {
+		case g.name == "":
+			fmt.Fprintf(w, "\nOptions:\n")
+		case params.commandByPrefix(g.name+".") != nil:
+			fmt.Fprintf(w, "\n%s options:\n", params.commandByPrefix(g.name+".").name)
+		default:
+			fmt.Fprintf(w, "\n%s options:\n", g.name)
+		}
+		for _, p := range g.params {
+			printParameter(w, p, width)
+		}
+		if i == 0 && u.ConfigFlag {
+			fmt.Fprintf(w, "  --%s string\n%s", ConfigFlag, wrapText("Configuration file", usageIndent, width))
+			fmt.Fprint(w, wrapText("Environment: "+ConfigEnv, usageIndent, width))
+		}
+		if i == 0 {
+			fmt.Fprintf(w, "  -h, --help\n%s", wrapText("Show this help", usageIndent, width))
+		}
+	}
+	if u.Footer != "" {
+		fmt.Fprintf(w, "\n%s", wrapText(u.Footer, "", width))
+	}
+}
+
+// width returns the width of the text
+func (u *Usage) width() int {
+	if u.Width > 0 {
+		return u.Width
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return 80
+}
+
+// argumentList returns the positional arguments for the usage line. Optional
+// arguments are in brackets.
+func argumentList(arguments []*parameter) string {
+	var ret []string
+	for _, p := range arguments {
+		name := p.usageName()
+		if p.slice {
+			name += "..."
+		}
+		if !p.required {
+			name = "[" + name + "]"
+		}
+		ret = append(ret, name)
+	}
+	return strings.Join(ret, " ")
+}
+
+// printCommands prints the list of commands with the descriptions
+func printCommands(w io.Writer, parent string, commands []*command, width int) {
+	names := make([]string, len(commands))
+	n := 0
+	for i, c := range commands {
+		names[i] = strings.TrimPrefix(c.name, parent+" ")
+		if len(names[i]) > n {
+			n = len(names[i])
+		}
+	}
+	indent := strings.Repeat(" ", n+4)
+	for i, c := range commands {
+		text := wrapText(c.description, indent, width)
+		fmt.Fprintf(w, "  %-*s%s", n+2, names[i], strings.TrimLeft(text, " "))
+	}
+}
+
+// usageGroups groups the parameters for a command by the nested structs. The
+// top level parameters are always first, the rest are in the same order as
+// the structs.
+func usageGroups(params *configParameters, cmd string) []usageGroup {
+	groups := []usageGroup{{}}
+	index := map[string]int{"": 0}
+	for i := range params.params {
+		p := &params.params[i]
+		if !params.isActive(p, cmd) || p.positional {
+			continue
+		}
+		name := ""
+		if n := strings.LastIndex(p.name, "."); n >= 0 {
+			name = p.name[:n]
+		}
+		gi, ok := index[name]
+		if !ok {
+			gi = len(groups)
+			index[name] = gi
+			groups = append(groups, usageGroup{name: name})
+		}
+		groups[gi].params = append(groups[gi].params, p)
+	}
+	return groups
+}
+
+const usageIndent = "        "
+
+// printParameter prints the help text for a single parameter
+func printParameter(w io.Writer, p *parameter, width int) {
+	flag := "  " + p.usageName()
+	if p.short != "" {
+		flag = "  -" + p.short + ", " + p.usageName()
+	}
+	if p.paramtype != boolType || p.slice || p.isMap || p.positional {
+		flag += " " + p.typeName()
+	}
+	fmt.Fprintln(w, flag)
+	text := p.description
+	if details := p.usageDetails(); len(details) > 0 {
+		text = strings.TrimSpace(fmt.Sprintf("%s (%s)", text, strings.Join(details, ", ")))
+	}
+	if text != "" {
+		fmt.Fprint(w, wrapText(text, usageIndent, width))
+	}
+	fmt.Fprint(w, wrapText("Environment: "+p.envName(), usageIndent, width))
+}
+
+// typeName returns the name of the parameter type for the help text
+func (p *parameter) typeName() string {
+	var name string
+	switch p.paramtype {
+	case durationType:
+		name = "duration"
+	case timeType:
+		name = "time"
+	case urlType:
+		name = "url"
+	case ipType:
+		name = "ip"
+	case ipNetType:
+		name = "cidr"
+	case regexpType:
+		name = "regexp"
+	case byteSizeType:
+		name = "size"
+	case customType:
+		name = reflect.New(p.fieldType).Interface().(Value).Type()
+	case textType:
+		name = "value"
+	default:
+		name = p.fieldType.Kind().String()
+	}
+	switch {
+	case p.slice:
+		return "[]" + name
+	case p.isMap:
+		return "map[string]" + name
+	}
+	return name
+}
+
+// usageDefault returns the default value formatted like the values of the
+// type, ie 64MiB for a ByteSize with the default 67108864. Strings are quoted.
+func (p *parameter) usageDefault() string {
+	if p.slice || p.isMap {
+		return p.defaultValue
+	}
+	v, err := p.parseValue(p.defaultValue)
+	if err != nil {
+		return p.defaultValue
+	}
+	if p.paramtype == stringType {
+		return fmt.Sprintf("%q", formatValue(v))
+	}
+	return formatValue(v)
+}
+
+// usageDetails returns the default value and the rules from the tag
+func (p *parameter) usageDetails() []string {
+	var ret []string
+	if p.required {
+		ret = append(ret, "required")
+	}
+	if len(p.aliases) > 0 {
+		ret = append(ret, "alias: --"+strings.Join(p.aliases, ", --"))
+	}
+	if p.defaultValue != "" {
+		ret = append(ret, "default: "+p.usageDefault())
+	}
+	if len(p.options) > 0 {
+		ret = append(ret, "options: "+strings.Join(p.options, "|"))
+	}
+	if p.minvalue != "" {
+		ret = append(ret, "min: "+p.minvalue)
+	}
+	if p.maxvalue != "" {
+		ret = append(ret, "max: "+p.maxvalue)
+	}
+	if p.minlen != "" {
+		ret = append(ret, "min length: "+p.minlen)
+	}
+	if p.maxlen != "" {
+		ret = append(ret, "max length: "+p.maxlen)
+	}
+	if p.file {
+		ret = append(ret, "existing file")
+	}
+	return ret
+}
+
+// wrapText wraps the text to the width. Each line is indented and ends with a
+// line break. Line breaks in the text are kept.
+func wrapText(text, indent string, width int) string {
+	var sb strings.Builder
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := indent
+		for _, word := range strings.Fields(paragraph) {
+			if len(line) > len(indent) && len(line)+1+len(word) > width {
+				sb.WriteString(line + "\n")
+				line = indent
+			}
+			if len(line) > len(indent) {
+				line += " "
+			}
+			line += word
+		}
+		sb.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return sb.String()
+}
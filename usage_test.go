@@ -0,0 +1,79 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsageDefaults(t *testing.T) {
+	var cfg struct {
+		Cache ByteSize `param:"desc=Cache size;default=67108864"`
+		Level logLevel `param:"desc=Log level;default=ERROR"`
+	}
+	buf := &bytes.Buffer{}
+	if err := (&Usage{}).Print(buf, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "(default: 64MiB)") || !strings.Contains(out, "(default: error)") {
+		t.Fatalf("Defaults aren't formatted:\n%s", out)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	var cfg struct {
+		LogType string            `param:"desc=Log type;default=plain;options=plain,fancy"`
+		Peers   []string          `param:"desc=Peers;minlen=1"`
+		Timeout time.Duration     `param:"desc=Timeout;default=1s;min=100ms;max=30s"`
+		Cache   ByteSize          `param:"desc=Cache size;default=64MiB"`
+		Labels  map[string]string `param:"desc=Labels"`
+		HTTP    struct {
+			Endpoint string `param:"desc=Server endpoint for the HTTP interface of the service;required"`
+		}
+	}
+	buf := &bytes.Buffer{}
+	usage := &Usage{Program: "svc", Description: "Runs the service.", Footer: "See the README.", Width: 40}
+	if err := usage.Print(buf, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, expected := range []string{
+		"Usage: svc [options]\n\nRuns the service.\n",
+		"  --log-type string\n        Log type (default: \"plain\",\n        options: plain|fancy)\n        Environment: LOG_TYPE\n",
+		"  --peers []string\n        Peers (min length: 1)\n",
+		"  --timeout duration\n",
+		"100ms, max: 30s)",
+		"  --cache size\n        Cache size (default: 64MiB)\n",
+		"  --labels map[string]string\n",
+		"  -h, --help\n",
+		"\nHTTP options:\n  --http-endpoint string\n",
+		"(required)\n        Environment: HTTP_ENDPOINT\n",
+		"\nSee the README.\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Usage doesn't contain %q:\n%s", expected, out)
+		}
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) > 40 {
+			t.Fatalf("Line isn't wrapped: %q", line)
+		}
+	}
+}
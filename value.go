@@ -0,0 +1,103 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+// Value is the interface for custom parameter types. Set is called with the
+// string from the command line, the environment or the configuration file,
+// String returns the current value and Type returns a short name for the type
+// that is used in help texts. Set must be implemented on the pointer type
+// since a new value is allocated for each call:
+//
+//  type LogLevel int
+//
+//  func (l *LogLevel) Set(s string) error { ... }
+//  func (l *LogLevel) String() string     { ... }
+//  func (l *LogLevel) Type() string       { return "level" }
+//
+// Types that implement encoding.TextUnmarshaler can be used as parameters
+// without implementing Value. If the type implements encoding.TextMarshaler
+// it will be used when the default is printed.
+type Value interface {
+	String() string
+	Set(string) error
+	Type() string
+}
+
+var (
+	valueInterface           = reflect.TypeOf((*Value)(nil)).Elem()
+	textUnmarshalerInterface = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// customInternalType returns the internal type for types implementing Value
+// or encoding.TextUnmarshaler
+func customInternalType(t reflect.Type) internalType {
+	if t == nil {
+		return invalidType
+	}
+	if reflect.PtrTo(t).Implements(valueInterface) {
+		return customType
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerInterface) {
+		return textType
+	}
+	return invalidType
+}
+
+// parseCustomValue parses a value for a Value or TextUnmarshaler type. The
+// returned value has the same type as t.
+func parseCustomValue(t reflect.Type, val string) (interface{}, error) {
+	v := reflect.New(t)
+	switch pv := v.Interface().(type) {
+	case Value:
+		if err := pv.Set(val); err != nil {
+			return nil, err
+		}
+	case encoding.TextUnmarshaler:
+		if err := pv.UnmarshalText([]byte(val)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%v is not a custom type", t)
+	}
+	return v.Elem().Interface(), nil
+}
+
+// formatValue returns the string representation of a value. Value,
+// TextMarshaler and Stringer types use their own representation.
+func formatValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	v := reflect.New(reflect.TypeOf(value))
+	v.Elem().Set(reflect.ValueOf(value))
+	switch pv := v.Interface().(type) {
+	case Value:
+		return pv.String()
+	case encoding.TextMarshaler:
+		if b, err := pv.MarshalText(); err == nil {
+			return string(b)
+		}
+	case fmt.Stringer:
+		return pv.String()
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,142 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"errors"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+type logLevel int
+
+func (l *logLevel) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "error":
+		*l = 2
+	default:
+		return errors.New("unknown log level")
+	}
+	return nil
+}
+
+func (l *logLevel) String() string {
+	return [...]string{"debug", "info", "error"}[*l]
+}
+
+func (l *logLevel) Type() string {
+	return "level"
+}
+
+type hostPort struct {
+	Host string
+	Port string
+}
+
+func (h *hostPort) UnmarshalText(text []byte) error {
+	parts := strings.Split(string(text), ":")
+	if len(parts) != 2 {
+		return errors.New("expected host:port")
+	}
+	h.Host, h.Port = parts[0], parts[1]
+	return nil
+}
+
+func (h hostPort) MarshalText() ([]byte, error) {
+	return []byte(h.Host + ":" + h.Port), nil
+}
+
+type customConfig struct {
+	Level    logLevel   `param:"desc=Log level;default=info"`
+	Endpoint hostPort   `param:"desc=Endpoint;default=localhost:80"`
+	Backends []hostPort `param:"desc=Backends"`
+}
+
+func TestCustomValueFlags(t *testing.T) {
+	var cfg customConfig
+	if err := NewFlag(&cfg, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != 1 || cfg.Endpoint.Host != "localhost" || cfg.Endpoint.Port != "80" {
+		t.Fatalf("Defaults aren't set: %+v", cfg)
+	}
+	if err := NewFlag(&cfg, []string{
+		"--level", "error",
+		"--endpoint", "example.com:443",
+		"--backends", "a:1,b:2",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != 2 || cfg.Endpoint.Host != "example.com" || len(cfg.Backends) != 2 || cfg.Backends[1].Port != "2" {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+	if err := newFlagWithErrorHandling(&cfg, []string{"--level", "trace"}, flag.ContinueOnError, false); err == nil {
+		t.Fatal("Expected error with invalid log level")
+	}
+	if err := newFlagWithErrorHandling(&cfg, []string{"--endpoint", "foo"}, flag.ContinueOnError, false); err == nil {
+		t.Fatal("Expected error with invalid endpoint")
+	}
+}
+
+func TestCustomValueEnvironment(t *testing.T) {
+	var cfg customConfig
+	os.Setenv("LEVEL", "debug")
+	os.Setenv("BACKENDS", "a:1")
+	defer os.Unsetenv("LEVEL")
+	defer os.Unsetenv("BACKENDS")
+	if err := NewEnv(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != 0 || len(cfg.Backends) != 1 || cfg.Backends[0].Host != "a" {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+}
+
+func TestCustomValueFile(t *testing.T) {
+	var cfg customConfig
+	file := `{
+		"level": "error",
+		"endpoint": "example.com:80",
+		"backends": ["a:1", "b:2"]
+	}`
+	if err := NewFile(&cfg, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != 2 || cfg.Endpoint.Host != "example.com" || len(cfg.Backends) != 2 {
+		t.Fatalf("Values aren't set: %+v", cfg)
+	}
+	if err := NewFile(&cfg, strings.NewReader(`{"level": 1}`)); err == nil {
+		t.Fatal("Expected error with invalid level")
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	if s := formatValue(logLevel(2)); s != "error" {
+		t.Fatalf("Value isn't formatted: %s", s)
+	}
+	if s := formatValue(hostPort{Host: "a", Port: "1"}); s != "a:1" {
+		t.Fatalf("TextMarshaler isn't formatted: %s", s)
+	}
+	if s := formatValue(12); s != "12" {
+		t.Fatalf("Int isn't formatted: %s", s)
+	}
+}
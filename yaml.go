@@ -0,0 +1,212 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NewYAMLFile populates a config struct with values from a YAML file. The
+// keys use the same naming and nesting as JSON files (see NewFile):
+//
+//  logType: plain
+//  http:
+//    endpoint: localhost:1234
+//    timeout: 10s
+//  peers:
+//    - a.example.com
+//    - b.example.com
+//
+// Errors include the line number in the file.
+func NewYAMLFile(config interface{}, reader io.Reader, opts ...FileOption) error {
+	return NewLoader(YAMLSource(reader, opts...)).Load(config)
+}
+
+// yamlSource reads values from a YAML file
+type yamlSource struct {
+	reader  io.Reader
+	options fileOptions
+}
+
+// YAMLSource returns a source that reads the parameters from a YAML file.
+// See NewYAMLFile for the format.
+func YAMLSource(reader io.Reader, opts ...FileOption) Source {
+	return &yamlSource{reader: reader, options: newFileOptions(opts)}
+}
+
+func (y *yamlSource) Name() string {
+	return SourceFile
+}
+
+func (y *yamlSource) Load(set *ParameterSet) error {
+	if y.reader == nil {
+		return ErrNilReader
+	}
+	var doc yaml.Node
+	if err := yaml.NewDecoder(y.reader).Decode(&doc); err != nil {
+		if err == io.EOF {
+			// Empty file
+			return nil
+		}
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := yamlAlias(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping at the top level", root.Line)
+	}
+	var unknown []string
+	if err := loadYAMLMapping(set, "", root, &unknown); err != nil {
+		return err
+	}
+	return y.options.checkUnknown(set.params, unknown)
+}
+
+// loadYAMLMapping sets the values in a mapping. Nested mappings are nested
+// structs unless the key is a parameter. Keys that don't match a parameter
+// are added to the unknown list.
+func loadYAMLMapping(set *ParameterSet, prefix string, node *yaml.Node, unknown *[]string) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := prefix + node.Content[i].Value
+		value := yamlAlias(node.Content[i+1])
+		para := set.params.getParameter(key)
+		if value.Kind == yaml.MappingNode && para == nil {
+			if err := loadYAMLMapping(set, key+".", value, unknown); err != nil {
+				return err
+			}
+			continue
+		}
+		if para == nil {
+			*unknown = append(*unknown, key)
+			continue
+		}
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+			continue
+		}
+		val, err := yamlValue(para, value)
+		if err != nil {
+			return err
+		}
+		if err := para.SetValue(val); err != nil {
+			return yamlError(value, err)
+		}
+		para.source = set.source
+	}
+	return nil
+}
+
+// yamlValue converts a node to the parameter's value
+func yamlValue(para *parameter, node *yaml.Node) (interface{}, error) {
+	switch {
+	case para.isMap:
+		if node.Kind != yaml.MappingNode {
+			return nil, yamlError(node, para.typeError(node.Value, "field %s must be a mapping", para.name))
+		}
+		values := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v, err := yamlScalar(para, node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			values[node.Content[i].Value] = v
+		}
+		return values, nil
+	case para.slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil, yamlError(node, para.typeError(node.Value, "field %s must be a list", para.name))
+		}
+		values := make([]interface{}, len(node.Content))
+		for i := range node.Content {
+			v, err := yamlScalar(para, node.Content[i])
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+	return yamlScalar(para, node)
+}
+
+// yamlScalar parses a scalar node. Scalars are parsed from their string
+// representation so integers and durations keep their precision.
+func yamlScalar(para *parameter, node *yaml.Node) (interface{}, error) {
+	node = yamlAlias(node)
+	if node.Kind != yaml.ScalarNode {
+		return nil, yamlError(node, para.typeError(node.Value, "field %s must be a single value", para.name))
+	}
+	value, err := yamlNumber(para, node)
+	if err != nil {
+		return nil, yamlError(node, err)
+	}
+	v, err := para.parseValue(value)
+	if err != nil {
+		return nil, yamlError(node, err)
+	}
+	return v, nil
+}
+
+// yamlNumber returns the value of a numeric scalar in base 10 for numeric
+// fields, ie 0x10 and 0o17 are returned as 16 and 15. Other scalars are
+// returned as they are.
+func yamlNumber(para *parameter, node *yaml.Node) (string, error) {
+	switch para.paramtype {
+	case intType, uintType, floatType, byteSizeType:
+	default:
+		return node.Value, nil
+	}
+	switch node.ShortTag() {
+	case "!!int":
+		var i int64
+		if err := node.Decode(&i); err == nil {
+			return strconv.FormatInt(i, 10), nil
+		}
+		var u uint64
+		if err := node.Decode(&u); err != nil {
+			return "", para.parseError(node.Value, err)
+		}
+		return strconv.FormatUint(u, 10), nil
+	case "!!float":
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return "", para.parseError(node.Value, err)
+		}
+		if para.paramtype == floatType {
+			return strconv.FormatFloat(f, 'g', -1, 64), nil
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	return node.Value, nil
+}
+
+// yamlAlias resolves aliases
+func yamlAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// yamlError adds the line number to the error
+func yamlError(node *yaml.Node, err error) error {
+	return fmt.Errorf("line %d: %w", node.Line, err)
+}
@@ -0,0 +1,156 @@
+package params
+
+//
+//Copyright 2019 Telenor Digital AS
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+//
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestYAMLFile(t *testing.T) {
+	config := parameters{}
+
+	if err := NewYAMLFile(&config, nil); err == nil {
+		t.Fatal("Expected error with nil reader")
+	}
+	if err := NewYAMLFile(&config, strings.NewReader("")); err != nil {
+		t.Fatal("Did not expect an error with an empty file: ", err)
+	}
+	if err := NewYAMLFile(&config, strings.NewReader("- a\n- b\n")); err == nil {
+		t.Fatal("Expected error when the file isn't a mapping")
+	}
+
+	file := `
+http:
+  endpoint: ":1234"
+deviceIO:
+  endpoint: localhost:4711
+myVal: 12
+myUint: 4711
+myBool: true
+myDuration: 12ms
+unknown: ignored
+`
+	if err := NewYAMLFile(&config, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if config.HTTP.Endpoint != ":1234" || config.DeviceIO.Endpoint != "localhost:4711" {
+		t.Fatalf("Nested values aren't set: %+v", config)
+	}
+	if config.MyVal != 12 || config.MyUint != 4711 || !config.MyBool || config.MyDuration != 12*time.Millisecond {
+		t.Fatalf("Values aren't set: %+v", config)
+	}
+}
+
+func TestYAMLListsAndMaps(t *testing.T) {
+	var cfg struct {
+		Hosts  []string          `param:"desc=Hosts;options=a,b,c"`
+		Big    []int64           `param:"desc=Big numbers"`
+		Tenant map[string]int    `param:"desc=Tenants"`
+		Labels map[string]string `param:"desc=Labels"`
+	}
+	file := `
+hosts: [a, c]
+big:
+  - 9007199254740993
+tenant:
+  a: 1
+  b: 2
+labels:
+  key: value
+`
+	if err := NewYAMLFile(&cfg, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Hosts) != 2 || cfg.Hosts[1] != "c" {
+		t.Fatalf("Hosts not set: %+v", cfg)
+	}
+	if len(cfg.Big) != 1 || cfg.Big[0] != 9007199254740993 {
+		t.Fatalf("Integers lose precision: %+v", cfg)
+	}
+	if cfg.Tenant["b"] != 2 || cfg.Labels["key"] != "value" {
+		t.Fatalf("Maps not set: %+v", cfg)
+	}
+	if err := NewYAMLFile(&cfg, strings.NewReader("hosts: a\n")); err == nil {
+		t.Fatal("Expected error when list isn't a list")
+	}
+	if err := NewYAMLFile(&cfg, strings.NewReader("tenant: [1, 2]\n")); err == nil {
+		t.Fatal("Expected error when map isn't a mapping")
+	}
+}
+
+func TestYAMLNumbers(t *testing.T) {
+	var cfg struct {
+		Port  int     `param:"desc=Port"`
+		Mode  uint32  `param:"desc=Mode"`
+		Count int     `param:"desc=Count"`
+		Ratio float64 `param:"desc=Ratio"`
+		Name  string  `param:"desc=Name"`
+	}
+	file := `
+port: 0x10
+mode: 0o17
+count: 1e2
+ratio: .5
+name: 0x10
+`
+	if err := NewYAMLFile(&cfg, strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 16 || cfg.Mode != 15 || cfg.Count != 100 || cfg.Ratio != 0.5 || cfg.Name != "0x10" {
+		t.Fatalf("Numbers aren't set: %+v", cfg)
+	}
+	if err := NewYAMLFile(&cfg, strings.NewReader("count: 1.5\n")); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("Expected invalid value but got %v", err)
+	}
+}
+
+func TestYAMLErrors(t *testing.T) {
+	var cfg struct {
+		Depth uint8 `param:"desc=Depth"`
+	}
+	err := NewYAMLFile(&cfg, strings.NewReader("# comment\ndepth: 300\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatalf("Expected error with line number but got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("Expected ErrInvalidValue but got %v", err)
+	}
+	if err := NewYAMLFile(&cfg, strings.NewReader("depth: [1\n")); err == nil {
+		t.Fatal("Expected syntax error")
+	}
+	if err := NewYAMLFile(&cfg, strings.NewReader("depth: ~\n")); err != nil || cfg.Depth != 0 {
+		t.Fatalf("Expected null to be ignored: %v", err)
+	}
+}
+
+func TestLoadYAML(t *testing.T) {
+	name := writeTempFile(t, "loadName: yaml\nloadPort: 8080\n")
+	defer os.Remove(name)
+	os.Rename(name, name+".yaml")
+	defer os.Remove(name + ".yaml")
+
+	var cfg loadConfig
+	if err := Load(&cfg, []string{"--config", name + ".yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LoadName != "yaml" || cfg.LoadPort != 8080 {
+		t.Fatalf("Values aren't set from YAML file: %+v", cfg)
+	}
+}